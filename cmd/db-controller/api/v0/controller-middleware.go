@@ -16,13 +16,16 @@ package v0
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/sakura-internet/distributed-mariadb-controller/pkg/controller"
 )
 
 const (
-	controllerStateCtxKey = "controllerState"
+	controllerStateCtxKey           = "controllerState"
+	controllerHeartbeatLagCtxKey    = "controllerHeartbeatLag"
+	controllerReplicaReadableCtxKey = "controllerReplicaReadable"
 )
 
 // UseControllerState is an echo middleware that injects the current state of the db-controller into othe request context.
@@ -30,6 +33,10 @@ func UseControllerState(ctrler *controller.Controller) func(echo.HandlerFunc) ec
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			c.Set(controllerStateCtxKey, ctrler.GetState())
+			c.Set(controllerReplicaReadableCtxKey, ctrler.ReplicaReadable())
+			if lag, ok := ctrler.HeartbeatLag(); ok {
+				c.Set(controllerHeartbeatLagCtxKey, lag)
+			}
 			return next(c)
 		}
 	}
@@ -44,3 +51,24 @@ func ExtractControllerState(c echo.Context) (controller.State, error) {
 
 	return v.(controller.State), nil
 }
+
+// ExtractHeartbeatLag is an utility for retrieving the heartbeat lag of the controller from request context.
+// the second return value is false if the lag hasn't been measured.
+func ExtractHeartbeatLag(c echo.Context) (time.Duration, bool) {
+	v := c.Get(controllerHeartbeatLagCtxKey)
+	if v == nil {
+		return 0, false
+	}
+
+	return v.(time.Duration), true
+}
+
+// ExtractReplicaReadable is an utility for retrieving whether the replica serves the read traffic from request context.
+func ExtractReplicaReadable(c echo.Context) (bool, error) {
+	v := c.Get(controllerReplicaReadableCtxKey)
+	if v == nil {
+		return false, fmt.Errorf("failed to get replica readability from context")
+	}
+
+	return v.(bool), nil
+}
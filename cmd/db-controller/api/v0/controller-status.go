@@ -22,6 +22,9 @@ import (
 
 type GetDBControllerStatusResponse struct {
 	State string `json:"state"`
+	// HeartbeatLagSeconds is the replication lag measured with the heartbeat table.
+	// it is omitted unless the controller has measured it in replica state.
+	HeartbeatLagSeconds *float64 `json:"heartbeat_lag_seconds,omitempty"`
 }
 
 // GetDBControllerStatus is an http handler that returns the current state of the db-controller.
@@ -32,5 +35,11 @@ func GetDBControllerStatus(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, &ErrorResponse{Message: err.Error()})
 	}
 
-	return c.JSON(http.StatusOK, GetDBControllerStatusResponse{State: string(state)})
+	res := GetDBControllerStatusResponse{State: string(state)}
+	if lag, ok := ExtractHeartbeatLag(c); ok {
+		sec := lag.Seconds()
+		res.HeartbeatLagSeconds = &sec
+	}
+
+	return c.JSON(http.StatusOK, res)
 }
@@ -33,3 +33,18 @@ func GSLBHealthCheckEndpoint(c echo.Context) error {
 
 	return c.NoContent(http.StatusOK)
 }
+
+// GSLBReplicaHealthCheckEndpoint responds 200 only when the replica serves the read traffic,
+// that is, the replica read traffic mode is enabled, the replication is running and the lag is under the limit.
+func GSLBReplicaHealthCheckEndpoint(c echo.Context) error {
+	readable, err := ExtractReplicaReadable(c)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &ErrorResponse{Message: err.Error()})
+	}
+
+	if !readable {
+		return c.NoContent(http.StatusServiceUnavailable)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
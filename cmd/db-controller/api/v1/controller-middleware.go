@@ -0,0 +1,47 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/controller"
+)
+
+const (
+	controllerCtxKey = "controller"
+)
+
+// UseController is an echo middleware that injects the db-controller into the request context.
+// unlike v0 API, v1 API operates the controller so the handlers need the controller itself.
+func UseController(ctrler *controller.Controller) func(echo.HandlerFunc) echo.HandlerFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(controllerCtxKey, ctrler)
+			return next(c)
+		}
+	}
+}
+
+// ExtractController is an utility for retrieving the controller from request context.
+func ExtractController(c echo.Context) (*controller.Controller, error) {
+	v := c.Get(controllerCtxKey)
+	if v == nil {
+		return nil, fmt.Errorf("failed to get controller from context")
+	}
+
+	return v.(*controller.Controller), nil
+}
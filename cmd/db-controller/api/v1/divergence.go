@@ -0,0 +1,52 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/controller"
+)
+
+type DivergenceResponse struct {
+	Diverged   bool                   `json:"diverged"`
+	Divergence *controller.Divergence `json:"divergence,omitempty"`
+}
+
+// GetDivergence is an http handler that returns whether the local MariaDB has diverged from the primary.
+// that assumes the `UseController` middleware before triggered this.
+func GetDivergence(c echo.Context) error {
+	ctrler, err := ExtractController(c)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &ErrorResponse{Message: err.Error()})
+	}
+
+	d := ctrler.GetDivergence()
+	return c.JSON(http.StatusOK, DivergenceResponse{Diverged: d != nil, Divergence: d})
+}
+
+// DeleteDivergence is an http handler that allows the controller to replicate from the primary again.
+// the operator must resolve the errant transactions before calling this.
+// that assumes the `UseController` middleware before triggered this.
+func DeleteDivergence(c echo.Context) error {
+	ctrler, err := ExtractController(c)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &ErrorResponse{Message: err.Error()})
+	}
+
+	ctrler.ClearDivergence()
+	return c.JSON(http.StatusOK, DivergenceResponse{Diverged: false})
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/command"
+)
+
+// defaultDryRunCommandLimit is the number of the commands returned when the limit is not given.
+const defaultDryRunCommandLimit = 100
+
+type GetDryRunResponse struct {
+	DryRun   bool                      `json:"dry_run"`
+	Commands []command.RecordedCommand `json:"commands"`
+}
+
+// GetDryRun is an http handler that returns the commands that the controller would have run in the dry-run mode.
+// the number of the commands can be given by the `limit` query parameter (0 means all).
+// that assumes the `UseController` middleware before triggered this.
+func GetDryRun(c echo.Context) error {
+	ctrler, err := ExtractController(c)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &ErrorResponse{Message: err.Error()})
+	}
+
+	limit := defaultDryRunCommandLimit
+	if q := c.QueryParam("limit"); q != "" {
+		limit, err = strconv.Atoi(q)
+		if err != nil || limit < 0 {
+			return c.JSON(http.StatusBadRequest, &ErrorResponse{Message: "limit must be a non-negative integer"})
+		}
+	}
+
+	return c.JSON(http.StatusOK, GetDryRunResponse{DryRun: ctrler.DryRun(), Commands: ctrler.DryRunCommands(limit)})
+}
@@ -0,0 +1,46 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/healthcheck"
+)
+
+type GetHealthResponse struct {
+	Healthy bool                 `json:"healthy"`
+	Checks  []healthcheck.Result `json:"checks"`
+}
+
+// GetHealth is an http handler that returns the latest result of each health check of MariaDB.
+// that assumes the `UseController` middleware before triggered this.
+func GetHealth(c echo.Context) error {
+	ctrler, err := ExtractController(c)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &ErrorResponse{Message: err.Error()})
+	}
+
+	results := ctrler.HealthCheckResults()
+	healthy := true
+	for _, res := range results {
+		if !res.Healthy {
+			healthy = false
+		}
+	}
+
+	return c.JSON(http.StatusOK, GetHealthResponse{Healthy: healthy, Checks: results})
+}
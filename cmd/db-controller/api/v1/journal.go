@@ -0,0 +1,55 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/controller"
+)
+
+// defaultJournalLimit is the number of the journal entries returned when the limit is not given.
+const defaultJournalLimit = 100
+
+type GetJournalResponse struct {
+	Entries []controller.JournalEntry `json:"entries"`
+}
+
+// GetJournal is an http handler that returns the recent state transitions of the controller.
+// the number of the entries can be given by the `limit` query parameter (0 means all).
+// that assumes the `UseController` middleware before triggered this.
+func GetJournal(c echo.Context) error {
+	ctrler, err := ExtractController(c)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &ErrorResponse{Message: err.Error()})
+	}
+
+	limit := defaultJournalLimit
+	if q := c.QueryParam("limit"); q != "" {
+		limit, err = strconv.Atoi(q)
+		if err != nil || limit < 0 {
+			return c.JSON(http.StatusBadRequest, &ErrorResponse{Message: "limit must be a non-negative integer"})
+		}
+	}
+
+	entries, err := ctrler.JournalEntries(limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &ErrorResponse{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, GetJournalResponse{Entries: entries})
+}
@@ -0,0 +1,56 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/controller"
+)
+
+type MaintenanceResponse struct {
+	State string `json:"state"`
+}
+
+// PostMaintenance is an http handler that freezes the state machine of the controller.
+// that assumes the `UseController` middleware before triggered this.
+func PostMaintenance(c echo.Context) error {
+	return requestMaintenance(c, true)
+}
+
+// DeleteMaintenance is an http handler that unfreezes the state machine of the controller.
+// the state is decided in the next loop of the controller.
+// that assumes the `UseController` middleware before triggered this.
+func DeleteMaintenance(c echo.Context) error {
+	return requestMaintenance(c, false)
+}
+
+func requestMaintenance(c echo.Context, enter bool) error {
+	ctrler, err := ExtractController(c)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &ErrorResponse{Message: err.Error()})
+	}
+
+	if err := ctrler.RequestMaintenance(c.Request().Context(), enter); err != nil {
+		if errors.Is(err, controller.ErrMaintenanceNotAllowed) || errors.Is(err, controller.ErrNotInMaintenance) {
+			return c.JSON(http.StatusConflict, &ErrorResponse{Message: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, &ErrorResponse{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, MaintenanceResponse{State: string(ctrler.GetState())})
+}
@@ -0,0 +1,46 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/controller"
+)
+
+type PostSwitchoverResponse struct {
+	State string `json:"state"`
+}
+
+// PostSwitchover is an http handler that hands over the primary role to the replica.
+// the handler responds after the handoff finished.
+// that assumes the `UseController` middleware before triggered this.
+func PostSwitchover(c echo.Context) error {
+	ctrler, err := ExtractController(c)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &ErrorResponse{Message: err.Error()})
+	}
+
+	if err := ctrler.RequestSwitchover(c.Request().Context()); err != nil {
+		if errors.Is(err, controller.ErrSwitchoverNotPrimary) || errors.Is(err, controller.ErrSwitchoverNoReplica) {
+			return c.JSON(http.StatusConflict, &ErrorResponse{Message: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, &ErrorResponse{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, PostSwitchoverResponse{State: string(ctrler.GetState())})
+}
@@ -17,6 +17,14 @@ package main
 import (
 	"flag"
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/controller"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/hook"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/notify"
 )
 
 var (
@@ -45,6 +53,9 @@ var (
 	bgpPeer1AsnFlag  int
 	bgpPeer2AddrFlag string
 	bgpPeer2AsnFlag  int
+	// bgpPeersFlag is a cli-flag that specifies the comma-separated bgp peers in the form of "address:asn".
+	// that is used for the cluster of three or more nodes instead of bgpPeerXAddrFlag and bgpPeerXAsn.
+	bgpPeersFlag string
 	// gobgpGrpcPortFlag is a cli-flag that specifies port of gobgp gRPC
 	gobgpGrpcPortFlag int
 
@@ -56,7 +67,87 @@ var (
 	prometheusExporterPortFlag int
 	// dbReplicaSourcePortFlag is a cli-flag that specifies the port of primary as replication source.
 	dbReplicaSourcePortFlag int
+	// switchoverTimeoutSecondFlag is a cli-flag that specifies the time limit for waiting the replica catches up in the switchover.
+	switchoverTimeoutSecondFlag int
+	// shutdownPolicyFlag is a cli-flag that specifies whether the primary hands over the role on the stop signal.
+	shutdownPolicyFlag string
+	// shutdownTimeoutSecondFlag is a cli-flag that specifies the time limit seconds of the handoff on the stop signal.
+	shutdownTimeoutSecondFlag int
+	// drainGracePeriodSecondFlag is a cli-flag that specifies the seconds the demoted primary waits for the in-flight transactions.
+	drainGracePeriodSecondFlag int
+
+	// fencingExecPathFlag is a cli-flag that specifies the script that fences the previous primary.
+	fencingExecPathFlag string
+	// fencingHTTPURLFlag is a cli-flag that specifies the HTTP endpoint that fences the previous primary.
+	fencingHTTPURLFlag string
+	// fencingTimeoutSecondFlag is a cli-flag that specifies the time limit seconds of the fencing.
+	fencingTimeoutSecondFlag int
+	// fencingPolicyFlag is a cli-flag that specifies whether the promotion is blocked when the fencing fails.
+	fencingPolicyFlag string
+
+	// hooksFlag is a cli-flag that specifies the lifecycle hooks around the state transitions.
+	hooksFlag string
+	// hookTimeoutSecondFlag is a cli-flag that specifies the default time limit seconds of each hook.
+	hookTimeoutSecondFlag int
+
+	// webhookURLsFlag is a cli-flag that specifies the HTTP endpoints that receive the events.
+	webhookURLsFlag string
+	// webhookSecretFilePathFlag is a cli-flag that specifies the filepath of the secret that signs the webhook requests.
+	webhookSecretFilePathFlag string
+	// webhookTimeoutSecondFlag is a cli-flag that specifies the time limit seconds of each webhook request.
+	webhookTimeoutSecondFlag int
+	// webhookMaxRetriesFlag is a cli-flag that specifies the maximum retries of the failed webhook request.
+	webhookMaxRetriesFlag int
+
+	// priorityFlag is a cli-flag that specifies the promotion priority of this node.
+	priorityFlag int
+	// failbackStabilizationSecondFlag is a cli-flag that specifies the seconds the preferred replica must stay replica before the failback.
+	failbackStabilizationSecondFlag int
+	// failbackWindowsFlag is a cli-flag that specifies the time ranges that allow the failback.
+	failbackWindowsFlag string
+	// promotionMaxLagSecondFlag is a cli-flag that specifies the maximum replication lag seconds that allows the promotion.
+	promotionMaxLagSecondFlag int
+	// promotionGTIDWaitTimeoutSecondFlag is a cli-flag that specifies the time limit seconds for waiting the relay log is applied.
+	promotionGTIDWaitTimeoutSecondFlag int
+	// quorumMembersFlag is a cli-flag that specifies the voters of the quorum with their weights.
+	quorumMembersFlag string
+	// transitionConfirmationsFlag is a cli-flag that specifies the consecutive loops that confirm each transition.
+	transitionConfirmationsFlag string
+	// promotionTimeoutPolicyFlag is a cli-flag that specifies whether the candidate is promoted when the wait times out.
+	promotionTimeoutPolicyFlag string
+	// healthCheckTimeoutSecondFlag is a cli-flag that specifies the time limit seconds of each health check.
+	healthCheckTimeoutSecondFlag int
+	// healthCheckFailureThresholdFlag is a cli-flag that specifies the consecutive failures that make a health check unhealthy.
+	healthCheckFailureThresholdFlag int
+	// healthCheckMaxQueryLatencyMillisecondFlag is a cli-flag that specifies the maximum latency of "SELECT 1".
+	healthCheckMaxQueryLatencyMillisecondFlag int
+	// healthCheckMinFreeMegabytesFlag is a cli-flag that specifies the minimum free space of the watched filesystems.
+	healthCheckMinFreeMegabytesFlag int
+	// healthCheckMinFreeInodesFlag is a cli-flag that specifies the minimum free inodes of the watched filesystems.
+	healthCheckMinFreeInodesFlag int
+	// mariaDBDataDirFlag is a cli-flag that specifies the datadir of MariaDB.
+	mariaDBDataDirFlag string
+	// mariaDBBinlogDirFlag is a cli-flag that specifies the binlog directory of MariaDB.
+	mariaDBBinlogDirFlag string
+	// mariaDBRelayLogDirFlag is a cli-flag that specifies the relay-log directory of MariaDB.
+	mariaDBRelayLogDirFlag string
+	// replicaReadPortFlag is a cli-flag that specifies the port that accepts the read traffic on the replica.
+	replicaReadPortFlag int
+	// replicaReadSourcesFlag is a cli-flag that specifies the sources allowed to read from the replica.
+	replicaReadSourcesFlag string
+	// replicaReadMaxLagSecondFlag is a cli-flag that specifies the maximum replication lag seconds that the replica serves the read traffic.
+	replicaReadMaxLagSecondFlag int
+	// reconcileTimeoutSecondFlag is a cli-flag that specifies the time limit seconds for waiting the BGP neighbors on startup.
+	reconcileTimeoutSecondFlag int
 
+	// adoptRunningMariaDBFlag is a cli-flag that enables adopting the running MariaDB on startup.
+	adoptRunningMariaDBFlag bool
+	// dryRunFlag is a cli-flag that makes the db-controller observe-only.
+	dryRunFlag bool
+	// enableReplicaReadTrafficFlag is a cli-flag that enables the read traffic to the replica.
+	enableReplicaReadTrafficFlag bool
+	// enableFailbackFlag is a cli-flag that enables the automatic failback to the higher-priority replica.
+	enableFailbackFlag bool
 	// enablePrometheusExporterFlag is a cli-flag that enables the prometheus exporter.
 	enablePrometheusExporterFlag bool
 	// enableHTTPAPIFlag is a cli-flag that enables the http api server.
@@ -75,11 +166,46 @@ func parseAllFlags(args []string) error {
 	fs.StringVar(&dbReplicaUserNameFlag, "db-replica-user-name", "repl", "the username for replication")
 	fs.StringVar(&bgpPeer1AddrFlag, "bgp-peer1-addr", "", "the address of bgp peer#1")
 	fs.StringVar(&bgpPeer2AddrFlag, "bgp-peer2-addr", "", "the address of bgp peer#2")
+	fs.StringVar(&bgpPeersFlag, "bgp-peers", "", "the comma-separated bgp peers in the form of address:asn(for example 10.0.0.2:65001,10.0.0.3:65002). that overrides --bgp-peer1-*/--bgp-peer2-*")
+	fs.StringVar(&fencingExecPathFlag, "fencing-exec-path", "", "the script that fences the previous primary (the address is given as the first argument)")
+	fs.StringVar(&fencingHTTPURLFlag, "fencing-http-url", "", "the HTTP endpoint that fences the previous primary")
+	fs.StringVar(&hooksFlag, "hooks", "", "the comma-separated lifecycle hooks in the form of point=path[:mode[:timeout-second]](for example pre-promote=/usr/local/bin/flush-cache:blocking:10). the points are pre-promote/post-promote/pre-demote/post-demote/enter-fault/enter-replica, and the modes are blocking/advisory")
+	fs.StringVar(&webhookURLsFlag, "webhook-urls", "", "the comma-separated HTTP endpoints that receive the events such as the state transitions as JSON")
+	fs.StringVar(&webhookSecretFilePathFlag, "webhook-secret-filepath", "", "the filepath of the secret that signs the webhook requests with HMAC-SHA256. empty sends the requests without the signature")
+	fs.StringVar(&failbackWindowsFlag, "failback-windows", "", "the comma-separated time ranges that allow the failback(for example 01:00-05:00,22:00-23:30). empty allows any time")
+	fs.StringVar(&quorumMembersFlag, "quorum-members", "", "the comma-separated voters of the quorum in the form of address=weight including this node(for example 192.0.2.1=1,10.0.0.1=1,10.0.0.2=1). empty means any visible neighbor makes the quorum")
+	fs.StringVar(&transitionConfirmationsFlag, "transition-confirmations", "", "the comma-separated consecutive loops that confirm each transition(for example primary:fault=3,replica:candidate=2). empty makes every transition immediate")
+	fs.StringVar(&promotionTimeoutPolicyFlag, "promotion-timeout-policy", "stay-candidate", "the policy when the relay log isn't applied in time(stay-candidate/promote)")
+	fs.StringVar(&mariaDBDataDirFlag, "mariadb-datadir", "/var/lib/mysql", "the datadir of MariaDB")
+	fs.StringVar(&mariaDBBinlogDirFlag, "mariadb-binlog-dir", "", "the binlog directory of MariaDB if it is not in the datadir")
+	fs.StringVar(&mariaDBRelayLogDirFlag, "mariadb-relaylog-dir", "", "the relay-log directory of MariaDB if it is not in the datadir")
+	fs.StringVar(&replicaReadSourcesFlag, "replica-read-sources", "", "the comma-separated addresses or prefixes allowed to read from the replica(for example 192.0.2.10,198.51.100.0/24). empty allows any source")
+	fs.StringVar(&shutdownPolicyFlag, "shutdown-policy", "fault", "the policy on the stop signal(fault/handoff/detach). detach keeps MariaDB running for the restart of db-controller")
+	fs.StringVar(&fencingPolicyFlag, "fencing-policy", "required", "the policy on the fencing failure(required/best-effort)")
 
 	fs.IntVar(&mainPollingSpanSecondFlag, "main-polling-span-second", 4, "the span seconds of the loop in main.go")
 	fs.IntVar(&httpAPIServerPortFlag, "http-api-server-port", 54545, "the port the http api server listens")
 	fs.IntVar(&prometheusExporterPortFlag, "prometheus-exporter-port", 50505, "the port the prometheus exporter listens")
 	fs.IntVar(&dbReplicaSourcePortFlag, "db-replica-source-port", 13306, "the port of primary as replication source")
+	fs.IntVar(&switchoverTimeoutSecondFlag, "switchover-timeout-second", 30, "the time limit seconds for waiting the replica catches up in the switchover")
+	fs.IntVar(&shutdownTimeoutSecondFlag, "shutdown-timeout-second", 30, "the time limit seconds of the handoff on the stop signal")
+	fs.IntVar(&drainGracePeriodSecondFlag, "drain-grace-period-second", 0, "the seconds the demoted primary rejects the new connections and waits for the in-flight transactions before killing the client sessions(0 disables the draining)")
+	fs.IntVar(&fencingTimeoutSecondFlag, "fencing-timeout-second", 30, "the time limit seconds of the fencing")
+	fs.IntVar(&hookTimeoutSecondFlag, "hook-timeout-second", 10, "the default time limit seconds of each hook")
+	fs.IntVar(&webhookTimeoutSecondFlag, "webhook-timeout-second", 5, "the time limit seconds of each webhook request")
+	fs.IntVar(&webhookMaxRetriesFlag, "webhook-max-retries", 3, "the maximum retries of the failed webhook request with the exponential backoff")
+	fs.IntVar(&priorityFlag, "priority", 0, "the promotion priority of this node(0-65535). the higher one is preferred in the election")
+	fs.IntVar(&failbackStabilizationSecondFlag, "failback-stabilization-second", 300, "the seconds the preferred replica must stay replica before the failback")
+	fs.IntVar(&promotionMaxLagSecondFlag, "promotion-max-lag-second", 0, "the maximum replication lag seconds that allows the promotion(0 means no limit)")
+	fs.IntVar(&promotionGTIDWaitTimeoutSecondFlag, "promotion-gtid-wait-timeout-second", 10, "the time limit seconds for waiting the relay log is applied before the promotion")
+	fs.IntVar(&healthCheckTimeoutSecondFlag, "health-check-timeout-second", 3, "the time limit seconds of each health check")
+	fs.IntVar(&healthCheckFailureThresholdFlag, "health-check-failure-threshold", 3, "the consecutive failures that make a health check unhealthy(the systemd check is always 1)")
+	fs.IntVar(&healthCheckMaxQueryLatencyMillisecondFlag, "health-check-max-query-latency-millisecond", 1000, "the maximum latency milliseconds of \"SELECT 1\"")
+	fs.IntVar(&healthCheckMinFreeMegabytesFlag, "health-check-min-free-megabytes", 1024, "the minimum free megabytes of the datadir and the binlog/relay-log directories")
+	fs.IntVar(&healthCheckMinFreeInodesFlag, "health-check-min-free-inodes", 10000, "the minimum free inodes of the datadir and the binlog/relay-log directories")
+	fs.IntVar(&replicaReadPortFlag, "replica-read-port", 0, "the port that accepts the read traffic on the replica(0 means --db-serving-port)")
+	fs.IntVar(&replicaReadMaxLagSecondFlag, "replica-read-max-lag-second", 10, "the maximum replication lag seconds that the replica serves the read traffic(0 means no limit)")
+	fs.IntVar(&reconcileTimeoutSecondFlag, "reconcile-timeout-second", 10, "the time limit seconds for waiting the bgp neighbors on startup")
 	fs.IntVar(&dbServingPortFlag, "db-serving-port", 3306, "the port of database service")
 	fs.IntVar(&bgpLocalAsnFlag, "bgp-local-asn", 0, "the as number of local")
 	fs.IntVar(&bgpPeer1AsnFlag, "bgp-peer1-asn", 0, "the asn of bgp peer#1")
@@ -88,6 +214,10 @@ func parseAllFlags(args []string) error {
 	fs.IntVar(&bgpKeepaliveIntervalSecFlag, "bgp-keepalive-interval-sec", 3, "the interval seconds of bgp keepalive")
 	fs.IntVar(&gobgpGrpcPortFlag, "gobgp-grpc-port", 50051, "the listen port of gobgp gRPC")
 
+	fs.BoolVar(&adoptRunningMariaDBFlag, "adopt-running-mariadb", true, "adopts the running MariaDB on startup if the last state is still safe")
+	fs.BoolVar(&dryRunFlag, "dry-run", false, "runs the controller loop and advertises the state over bgp, but only records the commands that change systemd, nftables and MariaDB")
+	fs.BoolVar(&enableReplicaReadTrafficFlag, "replica-read-traffic", false, "makes the replica accept the read traffic while read_only is on")
+	fs.BoolVar(&enableFailbackFlag, "failback", false, "enables the automatic failback to the higher-priority replica")
 	fs.BoolVar(&enablePrometheusExporterFlag, "prometheus-exporter", true, "enables the prometheus exporter")
 	fs.BoolVar(&enableHTTPAPIFlag, "http-api", true, "enables the http api server")
 
@@ -104,17 +234,183 @@ func validateAllFlags() error {
 		return fmt.Errorf("--prometheus-exporter-port must be the range of uint16(tcp port)")
 	}
 
+	if switchoverTimeoutSecondFlag <= 0 {
+		return fmt.Errorf("--switchover-timeout-second must be positive")
+	}
+
+	if hookTimeoutSecondFlag <= 0 {
+		return fmt.Errorf("--hook-timeout-second must be positive")
+	}
+
+	if _, err := hook.ParseHooks(hooksFlag, time.Second*time.Duration(hookTimeoutSecondFlag)); err != nil {
+		return fmt.Errorf("--hooks is invalid: %w", err)
+	}
+
+	if _, err := notify.ParseWebhookURLs(webhookURLsFlag); err != nil {
+		return fmt.Errorf("--webhook-urls is invalid: %w", err)
+	}
+
+	if webhookTimeoutSecondFlag <= 0 {
+		return fmt.Errorf("--webhook-timeout-second must be positive")
+	}
+
+	if webhookMaxRetriesFlag < 0 {
+		return fmt.Errorf("--webhook-max-retries must not be negative")
+	}
+
+	switch controller.ShutdownPolicy(shutdownPolicyFlag) {
+	case controller.ShutdownPolicyFault, controller.ShutdownPolicyHandoff, controller.ShutdownPolicyDetach:
+	default:
+		return fmt.Errorf("--shutdown-policy must be one of fault/handoff/detach")
+	}
+
+	if shutdownTimeoutSecondFlag <= 0 {
+		return fmt.Errorf("--shutdown-timeout-second must be positive")
+	}
+
+	if drainGracePeriodSecondFlag < 0 {
+		return fmt.Errorf("--drain-grace-period-second must not be negative")
+	}
+
+	if fencingExecPathFlag != "" && fencingHTTPURLFlag != "" {
+		return fmt.Errorf("--fencing-exec-path and --fencing-http-url are mutually exclusive")
+	}
+
+	if fencingPolicyFlag != string(controller.FencingPolicyRequired) && fencingPolicyFlag != string(controller.FencingPolicyBestEffort) {
+		return fmt.Errorf("--fencing-policy must be one of required/best-effort")
+	}
+
+	if fencingTimeoutSecondFlag <= 0 {
+		return fmt.Errorf("--fencing-timeout-second must be positive")
+	}
+
+	if priorityFlag < 0 || 65535 < priorityFlag {
+		return fmt.Errorf("--priority must be the range of uint16")
+	}
+
+	if failbackStabilizationSecondFlag < 0 {
+		return fmt.Errorf("--failback-stabilization-second must not be negative")
+	}
+
+	if _, err := controller.ParseFailbackWindows(failbackWindowsFlag); err != nil {
+		return fmt.Errorf("--failback-windows is invalid: %w", err)
+	}
+
+	if _, err := controller.ParseQuorumMembers(quorumMembersFlag); err != nil {
+		return fmt.Errorf("--quorum-members is invalid: %w", err)
+	}
+
+	if _, err := controller.ParseTransitionConfirmations(transitionConfirmationsFlag); err != nil {
+		return fmt.Errorf("--transition-confirmations is invalid: %w", err)
+	}
+
+	if replicaReadPortFlag < 0 || 65535 < replicaReadPortFlag {
+		return fmt.Errorf("--replica-read-port must be the range of uint16(tcp port)")
+	}
+
+	if _, err := controller.ParseReplicaReadSources(replicaReadSourcesFlag); err != nil {
+		return fmt.Errorf("--replica-read-sources is invalid: %w", err)
+	}
+
+	if replicaReadMaxLagSecondFlag < 0 {
+		return fmt.Errorf("--replica-read-max-lag-second must not be negative")
+	}
+
+	if promotionMaxLagSecondFlag < 0 {
+		return fmt.Errorf("--promotion-max-lag-second must not be negative")
+	}
+
+	if promotionGTIDWaitTimeoutSecondFlag <= 0 {
+		return fmt.Errorf("--promotion-gtid-wait-timeout-second must be positive")
+	}
+
+	if promotionTimeoutPolicyFlag != string(controller.PromotionTimeoutPolicyStayCandidate) && promotionTimeoutPolicyFlag != string(controller.PromotionTimeoutPolicyPromote) {
+		return fmt.Errorf("--promotion-timeout-policy must be one of stay-candidate/promote")
+	}
+
+	if healthCheckTimeoutSecondFlag <= 0 {
+		return fmt.Errorf("--health-check-timeout-second must be positive")
+	}
+
+	if healthCheckFailureThresholdFlag <= 0 {
+		return fmt.Errorf("--health-check-failure-threshold must be positive")
+	}
+
+	if healthCheckMaxQueryLatencyMillisecondFlag <= 0 {
+		return fmt.Errorf("--health-check-max-query-latency-millisecond must be positive")
+	}
+
+	if healthCheckMinFreeMegabytesFlag < 0 {
+		return fmt.Errorf("--health-check-min-free-megabytes must not be negative")
+	}
+
+	if healthCheckMinFreeInodesFlag < 0 {
+		return fmt.Errorf("--health-check-min-free-inodes must not be negative")
+	}
+
+	if reconcileTimeoutSecondFlag <= 0 {
+		return fmt.Errorf("--reconcile-timeout-second must be positive")
+	}
+
 	if bgpLocalAsnFlag == 0 {
 		return fmt.Errorf("--bgp-local-asan must be specified")
 	}
 
-	if bgpPeer1AddrFlag == "" || bgpPeer1AsnFlag == 0 || bgpPeer2AddrFlag == "" || bgpPeer2AsnFlag == 0 {
+	peers, err := bgpPeerSpecs()
+	if err != nil {
+		return err
+	}
+	// the anchor and at least one database node.
+	if len(peers) < 2 {
 		return fmt.Errorf("insufficient bgp peer")
 	}
 
 	return nil
 }
 
+// bgpPeerSpec is the address and the asn of a bgp peer given by the cli-flags.
+type bgpPeerSpec struct {
+	addr string
+	asn  uint32
+}
+
+// bgpPeerSpecs returns the bgp peers given by --bgp-peers,
+// or --bgp-peer1-*/--bgp-peer2-* if --bgp-peers is not given.
+func bgpPeerSpecs() ([]bgpPeerSpec, error) {
+	if bgpPeersFlag == "" {
+		if bgpPeer1AddrFlag == "" || bgpPeer1AsnFlag == 0 || bgpPeer2AddrFlag == "" || bgpPeer2AsnFlag == 0 {
+			return nil, fmt.Errorf("insufficient bgp peer")
+		}
+		return []bgpPeerSpec{
+			{addr: bgpPeer1AddrFlag, asn: uint32(bgpPeer1AsnFlag)},
+			{addr: bgpPeer2AddrFlag, asn: uint32(bgpPeer2AsnFlag)},
+		}, nil
+	}
+
+	peers := make([]bgpPeerSpec, 0)
+	for _, part := range strings.Split(bgpPeersFlag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		sep := strings.LastIndex(part, ":")
+		if sep < 0 {
+			return nil, fmt.Errorf("--bgp-peers is invalid: %s must be address:asn", part)
+		}
+		if net.ParseIP(part[:sep]) == nil {
+			return nil, fmt.Errorf("--bgp-peers is invalid: %s is not an ip address", part[:sep])
+		}
+		asn, err := strconv.ParseUint(part[sep+1:], 10, 32)
+		if err != nil || asn == 0 {
+			return nil, fmt.Errorf("--bgp-peers is invalid: %s is not an asn", part[sep+1:])
+		}
+		peers = append(peers, bgpPeerSpec{addr: part[:sep], asn: uint32(asn)})
+	}
+
+	return peers, nil
+}
+
 func isValidLogLevelFlag(l string) bool {
 	return l == "debug" || l == "info" || l == "warning" || l == "error"
 }
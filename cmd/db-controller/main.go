@@ -15,6 +15,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -23,6 +24,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"syscall"
@@ -33,9 +35,17 @@ import (
 	"github.com/labstack/gommon/log"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	apiv0 "github.com/sakura-internet/distributed-mariadb-controller/cmd/db-controller/api/v0"
+	apiv1 "github.com/sakura-internet/distributed-mariadb-controller/cmd/db-controller/api/v1"
 	"github.com/sakura-internet/distributed-mariadb-controller/pkg/bgpserver"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/command"
 	"github.com/sakura-internet/distributed-mariadb-controller/pkg/controller"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/fencing"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/healthcheck"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/hook"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
 	"github.com/sakura-internet/distributed-mariadb-controller/pkg/nftables"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/notify"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/systemd"
 	"github.com/vishvananda/netlink"
 )
 
@@ -62,9 +72,19 @@ func main() {
 
 	logger.Info("Hello, Starting db-controller.")
 
+	// in the dry-run mode, the side effects are only recorded and exposed via the http api.
+	var dryRunRecorder *command.Recorder
+	if dryRunFlag {
+		logger.Warn("db-controller runs in the dry-run mode. systemd, nftables and MariaDB are never changed.")
+		dryRunRecorder = command.NewRecorder(logger)
+	}
+
 	// for controlling the traffics that they're to the DB server port.
 	// the function returns nil if the expected chain is already exist.
 	nftConnect := nftables.NewDefaultConnector(logger)
+	if dryRunRecorder != nil {
+		nftConnect = nftables.NewDryRunConnector(nftConnect, dryRunRecorder)
+	}
 	if err := nftConnect.CreateChain(chainNameForDBAclFlag); err != nil {
 		panic(err)
 	}
@@ -81,19 +101,13 @@ func main() {
 		panic(err)
 	}
 
+	// the peers are already validated.
+	peerSpecs, _ := bgpPeerSpecs()
 	var bgpPeers []bgpserver.Peer
-	{
+	for _, peer := range peerSpecs {
 		bgpPeers = append(bgpPeers, bgpserver.Peer{
-			Neighbor:             bgpPeer1AddrFlag,
-			RemoteAS:             uint32(bgpPeer1AsnFlag),
-			RemotePort:           uint32(bgpServingPortFlag),
-			KeepaliveIntervalSec: uint64(bgpKeepaliveIntervalSecFlag),
-		})
-	}
-	{
-		bgpPeers = append(bgpPeers, bgpserver.Peer{
-			Neighbor:             bgpPeer2AddrFlag,
-			RemoteAS:             uint32(bgpPeer2AsnFlag),
+			Neighbor:             peer.addr,
+			RemoteAS:             peer.asn,
 			RemotePort:           uint32(bgpServingPortFlag),
 			KeepaliveIntervalSec: uint64(bgpKeepaliveIntervalSecFlag),
 		})
@@ -108,8 +122,22 @@ func main() {
 		bgpserver.WithPeers(bgpPeers),
 	)
 
-	c := controller.NewController(
-		logger,
+	// the connectors are shared by the controller and the health checks.
+	mariaDBConnect := mariadb.NewDefaultConnector(logger)
+	systemdConnect := systemd.NewDefaultConnector(logger)
+
+	// the members are already validated.
+	quorumMembers, _ := controller.ParseQuorumMembers(quorumMembersFlag)
+	if len(quorumMembers) != 0 && !slices.ContainsFunc(quorumMembers, func(m controller.QuorumMember) bool { return m.Address == myHostAddress }) {
+		logger.Warn("this node is not a member of the quorum. that never votes.", "address", myHostAddress)
+	}
+
+	// the hooks are already validated.
+	hooks, _ := hook.ParseHooks(hooksFlag, time.Second*time.Duration(hookTimeoutSecondFlag))
+
+	// the confirmations are already validated.
+	transitionConfirmations, _ := controller.ParseTransitionConfirmations(transitionConfirmationsFlag)
+	controllerConfigs := []controller.ControllerConfig{
 		controller.WithGlobalInterfaceName(globalInterfaceNameFlag),
 		controller.WithHostAddress(myHostAddress),
 		controller.WithDBServingPort(uint16(dbServingPortFlag)),
@@ -117,8 +145,75 @@ func main() {
 		controller.WithDBReplicaPassword(dbReplicaPassword),
 		controller.WithDBReplicaSourcePort(uint16(dbReplicaSourcePortFlag)),
 		controller.WithDBAclChainName(chainNameForDBAclFlag),
+		controller.WithSwitchoverTimeout(time.Second * time.Duration(switchoverTimeoutSecondFlag)),
+		controller.WithShutdownPolicy(controller.ShutdownPolicy(shutdownPolicyFlag), time.Second*time.Duration(shutdownTimeoutSecondFlag)),
+		controller.WithConnectionDraining(time.Second * time.Duration(drainGracePeriodSecondFlag)),
+		controller.WithFencingPolicy(controller.FencingPolicy(fencingPolicyFlag)),
+		controller.WithPriority(uint16(priorityFlag)),
+		controller.WithPromotionGate(
+			time.Second*time.Duration(promotionMaxLagSecondFlag),
+			time.Second*time.Duration(promotionGTIDWaitTimeoutSecondFlag),
+			controller.PromotionTimeoutPolicy(promotionTimeoutPolicyFlag),
+		),
+		controller.WithQuorum(quorumMembers),
+		controller.WithTransitionConfirmations(transitionConfirmations),
+		controller.WithJournalFilePath(filepath.Join(filepath.Dir(lockFilePathFlag), "journal")),
+		controller.WithAdoptRunningMariaDB(adoptRunningMariaDBFlag, time.Second*time.Duration(reconcileTimeoutSecondFlag)),
 		controller.WithBgpServerConnector(bgpServerConnect),
-	)
+		controller.WithMariaDBConnector(mariaDBConnect),
+		controller.WithSystemdConnector(systemdConnect),
+		controller.WithHealthChecks(newHealthChecks(mariaDBConnect, systemdConnect)...),
+		controller.WithHooks(hooks...),
+	}
+
+	if dryRunRecorder != nil {
+		controllerConfigs = append(controllerConfigs, controller.WithDryRun(dryRunRecorder))
+	}
+
+	if enableReplicaReadTrafficFlag {
+		// the sources are already validated.
+		replicaReadSources, _ := controller.ParseReplicaReadSources(replicaReadSourcesFlag)
+		controllerConfigs = append(controllerConfigs, controller.WithReplicaReadTraffic(
+			uint16(replicaReadPortFlag),
+			replicaReadSources,
+			time.Second*time.Duration(replicaReadMaxLagSecondFlag),
+		))
+	}
+
+	if enableFailbackFlag {
+		// the windows are already validated.
+		failbackWindows, _ := controller.ParseFailbackWindows(failbackWindowsFlag)
+		controllerConfigs = append(controllerConfigs, controller.WithFailback(time.Second*time.Duration(failbackStabilizationSecondFlag), failbackWindows))
+	}
+
+	// the fencer is optional. the previous primary is not fenced without it.
+	fencingTimeout := time.Second * time.Duration(fencingTimeoutSecondFlag)
+	if fencingExecPathFlag != "" {
+		controllerConfigs = append(controllerConfigs, controller.WithFencer(fencing.NewExecFencer(logger, fencingExecPathFlag, fencingTimeout)))
+	}
+	if fencingHTTPURLFlag != "" {
+		controllerConfigs = append(controllerConfigs, controller.WithFencer(fencing.NewHTTPFencer(logger, fencingHTTPURLFlag, fencingTimeout)))
+	}
+
+	// the urls are already validated.
+	webhookURLs, _ := notify.ParseWebhookURLs(webhookURLsFlag)
+	if len(webhookURLs) != 0 {
+		var webhookSecret []byte
+		if webhookSecretFilePathFlag != "" {
+			webhookSecret, err = readWebhookSecret(webhookSecretFilePathFlag)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		notifiers := make([]notify.Notifier, len(webhookURLs))
+		for i, url := range webhookURLs {
+			notifiers[i] = notify.NewWebhookNotifier(logger, url, webhookSecret, time.Second*time.Duration(webhookTimeoutSecondFlag), uint(webhookMaxRetriesFlag))
+		}
+		controllerConfigs = append(controllerConfigs, controller.WithNotifiers(notifiers...))
+	}
+
+	c := controller.NewController(logger, controllerConfigs...)
 
 	// start goroutines
 	ctx, cancel := context.WithCancel(context.Background())
@@ -160,6 +255,46 @@ func main() {
 	logger.Info("db-controller exited. see you again, bye.")
 }
 
+// newHealthChecks builds the health checks of MariaDB from the cli-flags.
+func newHealthChecks(mariaDBConnect mariadb.Connector, systemdConnect systemd.Connector) []healthcheck.Check {
+	timeout := time.Second * time.Duration(healthCheckTimeoutSecondFlag)
+	threshold := uint(healthCheckFailureThresholdFlag)
+
+	return []healthcheck.Check{
+		{
+			// the stopped service must be detected immediately.
+			HealthChecker:    healthcheck.NewSystemdUnitChecker(systemdConnect, mariadb.SystemdServiceName),
+			Timeout:          timeout,
+			FailureThreshold: 1,
+		},
+		{
+			HealthChecker:    healthcheck.NewTCPConnectChecker(fmt.Sprintf("127.0.0.1:%d", dbServingPortFlag)),
+			Timeout:          timeout,
+			FailureThreshold: threshold,
+		},
+		{
+			HealthChecker:    healthcheck.NewQueryLatencyChecker(mariaDBConnect, time.Millisecond*time.Duration(healthCheckMaxQueryLatencyMillisecondFlag)),
+			Timeout:          timeout,
+			FailureThreshold: threshold,
+		},
+		{
+			HealthChecker:    healthcheck.NewInnoDBChecker(mariaDBConnect),
+			Timeout:          timeout,
+			FailureThreshold: threshold,
+		},
+		{
+			// the exhausted or read-only storage makes MariaDB hang, so the primary must be demoted immediately.
+			HealthChecker: healthcheck.NewStorageChecker(
+				[]string{mariaDBDataDirFlag, mariaDBBinlogDirFlag, mariaDBRelayLogDirFlag},
+				uint64(healthCheckMinFreeMegabytesFlag)<<20,
+				uint64(healthCheckMinFreeInodesFlag),
+			),
+			Timeout:          timeout,
+			FailureThreshold: 1,
+		},
+	}
+}
+
 // startPrometheusExporterServer starts the HTTP server that serves the prometheus-exporter endpoint.
 func startPrometheusExporterServer(
 	ctx context.Context,
@@ -230,7 +365,20 @@ func startHTTPAPIServer(
 
 	e.HEAD("/healthcheck", apiv0.GSLBHealthCheckEndpoint)
 	e.GET("/healthcheck", apiv0.GSLBHealthCheckEndpoint)
+	e.HEAD("/healthcheck/replica", apiv0.GSLBReplicaHealthCheckEndpoint)
+	e.GET("/healthcheck/replica", apiv0.GSLBReplicaHealthCheckEndpoint)
 	e.GET("/status", apiv0.GetDBControllerStatus)
+
+	v1 := e.Group("/v1", apiv1.UseController(c))
+	v1.POST("/switchover", apiv1.PostSwitchover)
+	v1.POST("/maintenance", apiv1.PostMaintenance)
+	v1.DELETE("/maintenance", apiv1.DeleteMaintenance)
+	v1.GET("/journal", apiv1.GetJournal)
+	v1.GET("/health", apiv1.GetHealth)
+	v1.GET("/dry-run", apiv1.GetDryRun)
+	v1.GET("/divergence", apiv1.GetDivergence)
+	v1.DELETE("/divergence", apiv1.DeleteDivergence)
+
 	// Start server
 	addr := fmt.Sprintf(":%d", httpAPIServerPortFlag)
 
@@ -318,3 +466,13 @@ func readDBReplicaPassword(path string) (string, error) {
 
 	return strings.TrimSpace(string(b)), nil
 }
+
+// readWebhookSecret reads the contents from webhook secret file.
+func readWebhookSecret(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSpace(b), nil
+}
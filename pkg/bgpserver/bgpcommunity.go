@@ -32,3 +32,15 @@ func (c Community) String() string {
 	lower := uint32(c) & 0xffff
 	return fmt.Sprintf("%d:%d", upper, lower)
 }
+
+// LargeCommunity is the BGP large community (RFC 8092) that carries three 32-bit values.
+type LargeCommunity struct {
+	GlobalAdmin uint32
+	LocalData1  uint32
+	LocalData2  uint32
+}
+
+// String returns the human readable notation of the large community(for example 65200:0:100)
+func (c LargeCommunity) String() string {
+	return fmt.Sprintf("%d:%d:%d", c.GlobalAdmin, c.LocalData1, c.LocalData2)
+}
@@ -29,11 +29,20 @@ import (
 const (
 	// set dummy nexthop to advertise route because nexthop is meaningless.
 	dummyBgpRouteNexthop = "192.0.2.1"
+	// watchEventChannelSize is the buffer size of the event channel returned by Watch().
+	// the events are coalesced when the buffer is full because the receiver re-reads the whole RIB.
+	watchEventChannelSize = 1
 )
 
 type Route struct {
 	Prefix    netip.Prefix
 	Community Community
+	// AdditionalCommunities are advertised along with Community.
+	// ListPath() returns them as the separate routes that have the same prefix.
+	AdditionalCommunities []Community
+	// LargeCommunities are advertised along with Community.
+	// ListPath() returns each of them as the separate route that has no Community.
+	LargeCommunities []LargeCommunity
 }
 
 type Peer struct {
@@ -43,10 +52,28 @@ type Peer struct {
 	KeepaliveIntervalSec uint64
 }
 
+// EventType is the type of the event that is notified by Watch().
+type EventType uint
+
+const (
+	// EventTypeRoute notifies the best path is changed.
+	EventTypeRoute EventType = iota
+	// EventTypePeer notifies the state of a peer is changed.
+	EventTypePeer
+)
+
+// Event is the notification of the changes on the bgp server.
+type Event struct {
+	Type EventType
+}
+
 type Connector interface {
 	Start() error
 	AddPath(Route) error
 	ListPath() ([]Route, error)
+	// Watch subscribes the changes of the routes and the peers.
+	// the subscription is cancelled when the given context is done.
+	Watch(ctx context.Context) (<-chan Event, error)
 	Stop()
 }
 
@@ -179,12 +206,29 @@ func (bs *bgpServerConnector) AddPath(route Route) error {
 		attrNextHop, _ := apb.New(&gobgpapi.NextHopAttribute{
 			NextHop: dummyBgpRouteNexthop,
 		})
+		communities := []uint32{uint32(route.Community)}
+		for _, comm := range route.AdditionalCommunities {
+			communities = append(communities, uint32(comm))
+		}
 		attrCommunities, _ := apb.New(&gobgpapi.CommunitiesAttribute{
-			Communities: []uint32{
-				uint32(route.Community),
-			},
+			Communities: communities,
 		})
 		attrs = []*apb.Any{attrOrigin, attrNextHop, attrCommunities}
+
+		if len(route.LargeCommunities) != 0 {
+			largeCommunities := make([]*gobgpapi.LargeCommunity, 0, len(route.LargeCommunities))
+			for _, comm := range route.LargeCommunities {
+				largeCommunities = append(largeCommunities, &gobgpapi.LargeCommunity{
+					GlobalAdmin: comm.GlobalAdmin,
+					LocalData1:  comm.LocalData1,
+					LocalData2:  comm.LocalData2,
+				})
+			}
+			attrLargeCommunities, _ := apb.New(&gobgpapi.LargeCommunitiesAttribute{
+				Communities: largeCommunities,
+			})
+			attrs = append(attrs, attrLargeCommunities)
+		}
 	}
 
 	_, err = bs.server.AddPath(context.Background(), &gobgpapi.AddPathRequest{
@@ -226,16 +270,25 @@ func (bs *bgpServerConnector) ListPath() ([]Route, error) {
 					return
 				}
 
-				ca, ok := m.(*gobgpapi.CommunitiesAttribute)
-				if !ok {
-					continue
-				}
-
-				for _, comm := range ca.Communities {
-					routes = append(routes, Route{
-						Prefix:    prefix,
-						Community: Community(comm),
-					})
+				switch a := m.(type) {
+				case *gobgpapi.CommunitiesAttribute:
+					for _, comm := range a.Communities {
+						routes = append(routes, Route{
+							Prefix:    prefix,
+							Community: Community(comm),
+						})
+					}
+				case *gobgpapi.LargeCommunitiesAttribute:
+					for _, comm := range a.Communities {
+						routes = append(routes, Route{
+							Prefix: prefix,
+							LargeCommunities: []LargeCommunity{{
+								GlobalAdmin: comm.GlobalAdmin,
+								LocalData1:  comm.LocalData1,
+								LocalData2:  comm.LocalData2,
+							}},
+						})
+					}
 				}
 			}
 		}
@@ -247,6 +300,38 @@ func (bs *bgpServerConnector) ListPath() ([]Route, error) {
 	return routes, nil
 }
 
+func (bs *bgpServerConnector) Watch(ctx context.Context) (<-chan Event, error) {
+	ch := make(chan Event, watchEventChannelSize)
+	notify := func(ev Event) {
+		// never block the gobgp's goroutine.
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+
+	err := bs.server.WatchEvent(ctx, &gobgpapi.WatchEventRequest{
+		Table: &gobgpapi.WatchEventRequest_Table{
+			Filters: []*gobgpapi.WatchEventRequest_Table_Filter{
+				{Type: gobgpapi.WatchEventRequest_Table_Filter_BEST},
+			},
+		},
+		Peer: &gobgpapi.WatchEventRequest_Peer{},
+	}, func(r *gobgpapi.WatchEventResponse) {
+		switch r.GetEvent().(type) {
+		case *gobgpapi.WatchEventResponse_Table:
+			notify(Event{Type: EventTypeRoute})
+		case *gobgpapi.WatchEventResponse_Peer:
+			notify(Event{Type: EventTypePeer})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ch, nil
+}
+
 func (bs *bgpServerConnector) Stop() {
 	bs.server.Stop()
 }
@@ -14,15 +14,26 @@
 
 package bgpserver
 
-import "net/netip"
+import (
+	"context"
+	"net/netip"
+)
 
 type FakeBgpServerConnector struct {
 	RouteConfigured map[netip.Prefix]bool
+	// AdvertisedRoutes holds the last route given to AddPath() for each prefix.
+	AdvertisedRoutes map[netip.Prefix]Route
+	// Routes is returned by ListPath().
+	Routes []Route
+	// Events is returned by Watch(). tests can notify the events through it.
+	Events chan Event
 }
 
 func NewFakeBgpServerConnector() Connector {
 	return &FakeBgpServerConnector{
-		RouteConfigured: make(map[netip.Prefix]bool),
+		RouteConfigured:  make(map[netip.Prefix]bool),
+		AdvertisedRoutes: make(map[netip.Prefix]Route),
+		Events:           make(chan Event, 1),
 	}
 }
 
@@ -32,12 +43,17 @@ func (bs *FakeBgpServerConnector) Start() error {
 
 func (bs *FakeBgpServerConnector) AddPath(route Route) error {
 	bs.RouteConfigured[route.Prefix] = true
+	bs.AdvertisedRoutes[route.Prefix] = route
 
 	return nil
 }
 
 func (bs *FakeBgpServerConnector) ListPath() ([]Route, error) {
-	return nil, nil
+	return bs.Routes, nil
+}
+
+func (bs *FakeBgpServerConnector) Watch(ctx context.Context) (<-chan Event, error) {
+	return bs.Events, nil
 }
 
 func (bs *FakeBgpServerConnector) Stop() {
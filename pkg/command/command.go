@@ -16,7 +16,9 @@ package command
 
 import (
 	"context"
+	"os"
 	"os/exec"
+	"strings"
 	"time"
 )
 
@@ -26,3 +28,27 @@ func RunWithTimeout(timeout time.Duration, name string, args ...string) ([]byte,
 	defer cancel()
 	return exec.CommandContext(ctx, name, args...).Output()
 }
+
+// RunWithTimeoutAndEnv executes a command with timeout and the additional environment variables.
+// the command inherits the environment of this process as well.
+func RunWithTimeoutAndEnv(timeout time.Duration, env []string, name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), env...)
+	return cmd.CombinedOutput()
+}
+
+// RunWithTimeoutEnvAndStdin executes a command with timeout, the additional environment variables and the standard input.
+// unlike RunWithTimeoutAndEnv, only the standard output is returned as RunWithTimeout,
+// so the standard error is kept in exec.ExitError when the command fails.
+func RunWithTimeoutEnvAndStdin(timeout time.Duration, env []string, stdin string, name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), env...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	return cmd.Output()
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRecordedCommands is the number of the commands that the recorder holds.
+// the older commands are discarded.
+const maxRecordedCommands = 1000
+
+// RecordedCommand is a command that the dry-run connector would have run.
+type RecordedCommand struct {
+	Timestamp time.Time `json:"timestamp"`
+	// Component is the kind of the connector (e.g. systemd, nftables, mariadb).
+	Component string `json:"component"`
+	Command   string `json:"command"`
+}
+
+// Recorder records the commands instead of running them.
+// it is shared by the dry-run connectors and is safe for the concurrent use.
+type Recorder struct {
+	logger   *slog.Logger
+	m        sync.Mutex
+	commands []RecordedCommand
+}
+
+func NewRecorder(logger *slog.Logger) *Recorder {
+	return &Recorder{logger: logger, commands: make([]RecordedCommand, 0)}
+}
+
+// Record records the command that would have been run.
+func (r *Recorder) Record(component string, name string, args ...string) {
+	cmd := strings.Join(append([]string{name}, args...), " ")
+	r.logger.Info("dry-run: skip the command", "component", component, "command", cmd)
+
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.commands = append(r.commands, RecordedCommand{Timestamp: time.Now(), Component: component, Command: cmd})
+	if len(r.commands) > maxRecordedCommands {
+		r.commands = r.commands[len(r.commands)-maxRecordedCommands:]
+	}
+}
+
+// Commands returns the recorded commands in chronological order.
+// the function returns the last `limit` commands if limit is positive.
+func (r *Recorder) Commands(limit int) []RecordedCommand {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	commands := r.commands
+	if 0 < limit && limit < len(commands) {
+		commands = commands[len(commands)-limit:]
+	}
+	return append([]RecordedCommand{}, commands...)
+}
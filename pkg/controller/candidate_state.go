@@ -25,11 +25,28 @@ func (c *Controller) decideNextStateOnCandidate() State {
 		return StateFault
 	}
 
-	if c.currentNeighbors.candidateNodeExists() || c.currentNeighbors.primaryNodeExists() {
-		c.logger.Info("another candidate or primary exists. falling back to fault state.")
+	if c.currentNeighbors.primaryNodeExists() {
+		c.logger.Info("another primary exists. falling back to fault state.")
 		return StateFault
 	}
 
+	// the node that has applied the most transactions should be promoted to minimize the data loss.
+	if c.moreAdvancedNeighborExists() {
+		c.logger.Info("a more advanced neighbor exists. falling back to fault state.", "sequence", c.gtidSequence)
+		return StateFault
+	}
+
+	// the candidates converge on the winner of the election instead of all falling back.
+	if c.currentNeighbors.candidateNodeExists() {
+		if !c.winsElection() {
+			c.logger.Info("lost the election to another candidate. falling back to fault state.", "candidates", c.currentNeighbors[StateCandidate])
+			return StateFault
+		}
+
+		c.logger.Info("won the election. waiting for the other candidates to fall back.", "candidates", c.currentNeighbors[StateCandidate])
+		return StateCandidate
+	}
+
 	if c.readyToPrimary == readytoPrimaryJudgeOK {
 		return StatePrimary
 	}
@@ -38,6 +55,13 @@ func (c *Controller) decideNextStateOnCandidate() State {
 	return StateCandidate
 }
 
+// triggerRunOnStateKeepsCandidate keeps the advertised gtid sequence up to date
+// while the relay log is applied.
+func (c *Controller) triggerRunOnStateKeepsCandidate() error {
+	c.refreshGTIDAdvertisement()
+	return nil
+}
+
 // triggerRunOnStateChangesToCandidate transition to candidate in main loop.
 func (c *Controller) triggerRunOnStateChangesToCandidate() error {
 	// [STEP1]: setting MariaDB State.
@@ -17,6 +17,7 @@ package controller
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"math/rand"
 	"net/netip"
@@ -25,8 +26,13 @@ import (
 	"time"
 
 	"github.com/sakura-internet/distributed-mariadb-controller/pkg/bgpserver"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/command"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/fencing"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/healthcheck"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/hook"
 	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
 	"github.com/sakura-internet/distributed-mariadb-controller/pkg/nftables"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/notify"
 	"github.com/sakura-internet/distributed-mariadb-controller/pkg/systemd"
 )
 
@@ -34,22 +40,13 @@ import (
 type State string
 
 const (
-	StateInitial   State = "initial"
-	StateFault     State = "fault"
-	StateCandidate State = "candidate"
-	StatePrimary   State = "primary"
-	StateReplica   State = "replica"
-	StateAnchor    State = "anchor"
-)
-
-var (
-	controllerAllStates = map[State]bool{
-		StateInitial:   true,
-		StateFault:     true,
-		StatePrimary:   true,
-		StateCandidate: true,
-		StateReplica:   true,
-	}
+	StateInitial     State = "initial"
+	StateFault       State = "fault"
+	StateCandidate   State = "candidate"
+	StatePrimary     State = "primary"
+	StateReplica     State = "replica"
+	StateMaintenance State = "maintenance"
+	StateAnchor      State = "anchor"
 )
 
 // dbHealthCheckResult is the result of the mariadb's healthcheck
@@ -60,6 +57,12 @@ const (
 	dbHealthCheckResultNG
 )
 
+const (
+	// desynchronizationJitterMin and desynchronizationJitterRange specify the random sleep before becoming candidate.
+	desynchronizationJitterMin   = 1 * time.Second
+	desynchronizationJitterRange = 1 * time.Second
+)
+
 // readyToPrimaryJudge is the result of the judgement to be promoted to primary state.
 type readyToPrimaryJudge uint
 
@@ -71,27 +74,30 @@ const (
 )
 
 var (
-	bgpCommunityFault     = bgpserver.MustParseCommunity("65000:1")
-	bgpCommunityCandidate = bgpserver.MustParseCommunity("65000:2")
-	bgpCommunityPrimary   = bgpserver.MustParseCommunity("65000:3")
-	bgpCommunityReplica   = bgpserver.MustParseCommunity("65000:4")
-	bgpCommunityAnchor    = bgpserver.MustParseCommunity("65000:10")
+	bgpCommunityFault       = bgpserver.MustParseCommunity("65000:1")
+	bgpCommunityCandidate   = bgpserver.MustParseCommunity("65000:2")
+	bgpCommunityPrimary     = bgpserver.MustParseCommunity("65000:3")
+	bgpCommunityReplica     = bgpserver.MustParseCommunity("65000:4")
+	bgpCommunityMaintenance = bgpserver.MustParseCommunity("65000:5")
+	bgpCommunityAnchor      = bgpserver.MustParseCommunity("65000:10")
 )
 
 var (
 	bgpCommunityToState = map[bgpserver.Community]State{
-		bgpCommunityFault:     StateFault,
-		bgpCommunityCandidate: StateCandidate,
-		bgpCommunityPrimary:   StatePrimary,
-		bgpCommunityReplica:   StateReplica,
-		bgpCommunityAnchor:    StateAnchor,
+		bgpCommunityFault:       StateFault,
+		bgpCommunityCandidate:   StateCandidate,
+		bgpCommunityPrimary:     StatePrimary,
+		bgpCommunityReplica:     StateReplica,
+		bgpCommunityMaintenance: StateMaintenance,
+		bgpCommunityAnchor:      StateAnchor,
 	}
 	stateToBgpCommunity = map[State]bgpserver.Community{
-		StateFault:     bgpCommunityFault,
-		StateCandidate: bgpCommunityCandidate,
-		StatePrimary:   bgpCommunityPrimary,
-		StateReplica:   bgpCommunityReplica,
-		StateAnchor:    bgpCommunityAnchor,
+		StateFault:       bgpCommunityFault,
+		StateCandidate:   bgpCommunityCandidate,
+		StatePrimary:     bgpCommunityPrimary,
+		StateReplica:     bgpCommunityReplica,
+		StateMaintenance: bgpCommunityMaintenance,
+		StateAnchor:      bgpCommunityAnchor,
 	}
 )
 
@@ -111,6 +117,20 @@ type Controller struct {
 	dbReplicaPassword string
 	// dbAclChainName is the nftables chain name for database access control.
 	dbAclChainName string
+	// switchoverTimeout is the time limit for waiting the replica catches up in the switchover.
+	switchoverTimeout time.Duration
+	// shutdownPolicy specifies whether the primary hands over the role on the stop signal.
+	shutdownPolicy ShutdownPolicy
+	// shutdownTimeout is the time limit of the handoff on the stop signal.
+	shutdownTimeout time.Duration
+	// drainGracePeriod is the time that the demoted primary waits for the in-flight transactions. zero disables the draining.
+	drainGracePeriod time.Duration
+	// journalFilePath is the file that records the state transitions across the restarts.
+	journalFilePath string
+	// adoptRunningMariaDB enables the startup reconciliation that adopts the running MariaDB.
+	adoptRunningMariaDB bool
+	// reconcileTimeout is the time limit for waiting the BGP neighbors on startup.
+	reconcileTimeout time.Duration
 
 	// currentState is the current state of the controller.
 	// for prevending unexpected transition, the state isn't exposed.
@@ -131,6 +151,99 @@ type Controller struct {
 	currentMariaDBHealth dbHealthCheckResult
 	// readyToPrimary
 	readyToPrimary readyToPrimaryJudge
+	// switchoverRequestCh receives the switchover requests from the http-api goroutine.
+	switchoverRequestCh chan switchoverRequest
+	// maintenanceMode is true while the operator freezes the state machine.
+	maintenanceMode bool
+	// maintenanceRequestCh receives the maintenance requests from the http-api goroutine.
+	maintenanceRequestCh chan maintenanceRequest
+	// transitionTable is the state machine that the controller executes.
+	transitionTable TransitionTable
+	// replicationSource is the primary neighbor that this controller replicates from in replica state.
+	replicationSource neighbor
+	// replicationConfigured is true while the replication started in replica state is expected to be kept.
+	// that is cleared on entering fault state, because MariaDB is stopped and the replication is discarded.
+	replicationConfigured bool
+	// lastPrimaryNeighbor is the primary neighbor that the controller observed most recently.
+	// the neighbor is fenced before this controller is promoted to primary.
+	lastPrimaryNeighbor neighbor
+	// fencingPolicy specifies whether the promotion is blocked when the fencing fails.
+	fencingPolicy FencingPolicy
+	// priority is the promotion priority of this controller. the higher one is preferred.
+	// zero means no preference and isn't advertised.
+	priority uint16
+	// neighborPriorities holds the priorities advertised by the neighbors.
+	neighborPriorities map[neighbor]uint16
+	// gtidSequence is the total sequence number of gtid_current_pos observed in replica or candidate state.
+	gtidSequence uint64
+	// gtidSequenceKnown is true if gtidSequence is observed in the current loop.
+	gtidSequenceKnown bool
+	// advertisedGTIDSequence is the gtid sequence that has been advertised over BGP.
+	advertisedGTIDSequence uint64
+	// neighborGTIDSequences holds the gtid sequences advertised by the neighbors.
+	neighborGTIDSequences map[neighbor]uint64
+	// priorityYieldCount is the number of the consecutive loops that yields the candidacy.
+	priorityYieldCount uint
+	// eventDriven is true while the loop triggered by the bgp event is running.
+	// the per-loop counters advance only in the loops driven by the ticker,
+	// because a flap fires several events in a moment.
+	eventDriven bool
+	// failbackEnabled enables the automatic failback to the preferred replica.
+	failbackEnabled bool
+	// failbackStabilizationPeriod is the time that the preferred replica must stay replica before the failback.
+	failbackStabilizationPeriod time.Duration
+	// failbackWindows are the time ranges that allow the failback. empty means any time.
+	failbackWindows []FailbackWindow
+	// failbackCandidate is the preferred replica that is in the stabilization period.
+	failbackCandidate neighbor
+	// failbackCandidateSince is the time that the failbackCandidate is found.
+	failbackCandidateSince time.Time
+	// promotionMaxLag is the maximum replication lag that allows the promotion. zero disables the limit.
+	promotionMaxLag time.Duration
+	// promotionGTIDWaitTimeout is the time limit for waiting the relay log is applied before the promotion.
+	promotionGTIDWaitTimeout time.Duration
+	// promotionTimeoutPolicy specifies whether the candidate is promoted when the wait times out.
+	promotionTimeoutPolicy PromotionTimeoutPolicy
+	// lastReplicationLag is the replication lag observed most recently in replica state.
+	lastReplicationLag time.Duration
+	// lastReplicationLagKnown is true if lastReplicationLag has been observed.
+	lastReplicationLagKnown bool
+	// heartbeatLag is the replication lag measured with the heartbeat table in replica state.
+	heartbeatLag time.Duration
+	// heartbeatLagKnown is true if heartbeatLag has been measured.
+	heartbeatLagKnown bool
+	// epoch identifies the term of this controller as primary.
+	// it is advertised with the primary route and written in the heartbeat.
+	epoch uint64
+	// clusterEpoch is the highest epoch that this controller has observed.
+	// the primary neighbors that advertise the older epoch are stale.
+	clusterEpoch uint64
+	// neighborEpochs holds the epochs advertised by the primary neighbors.
+	neighborEpochs map[neighbor]uint64
+	// replicaReadEnabled enables the replica read traffic mode that accepts the read-only clients on the replica.
+	replicaReadEnabled bool
+	// replicaReadPort is the port that accepts the read traffic on the replica. zero means dbServingPort.
+	replicaReadPort uint16
+	// replicaReadSources are the addresses or prefixes allowed to read from the replica. empty means any source.
+	replicaReadSources []string
+	// replicaReadMaxLag is the maximum replication lag that the replica serves the read traffic. zero disables the limit.
+	replicaReadMaxLag time.Duration
+	// replicaReadable is true while the replica serves the read traffic, see updateReplicaReadable.
+	replicaReadable bool
+	// quorumMembers are the voters of the quorum. empty means any visible neighbor makes the quorum.
+	quorumMembers []QuorumMember
+	// transitionConfirmations holds the number of the consecutive loops that confirm each transition.
+	// the transitions not in the map are taken immediately.
+	transitionConfirmations map[Transition]uint
+	// pendingTransition is the transition that waits for the confirmations.
+	pendingTransition Transition
+	// pendingTransitionCount is the number of the loops that confirmed the pendingTransition.
+	pendingTransitionCount uint
+	// divergence is set when the local MariaDB has the transactions that the primary never saw.
+	// the controller refuses to be replica until the operator clears this.
+	divergence *Divergence
+	// dualPrimaryDetected is true while two or more primaries are visible, see detectDualPrimary.
+	dualPrimaryDetected bool
 
 	// nftablesConnector communicates with nftables.
 	nftablesConnector nftables.Connector
@@ -140,6 +253,19 @@ type Controller struct {
 	mariaDBConnector mariadb.Connector
 	// bgpServerConnector communicates with gobgp
 	bgpServerConnector bgpserver.Connector
+	// fencer isolates the previous primary before promotion. nil disables the fencing.
+	fencer fencing.Fencer
+	// healthCheckRunner runs the health checks of MariaDB.
+	healthCheckRunner *healthcheck.Runner
+	// hooks are the lifecycle hooks configured by the operator.
+	hooks []hook.Hook
+	// hookRunner runs the lifecycle hooks around the state transitions.
+	hookRunner *hook.Runner
+	// notifiers deliver the events such as the state transitions to the outside.
+	notifiers []notify.Notifier
+	// dryRunRecorder records the side effects instead of the connectors in the dry-run mode.
+	// nil means the controller runs normally.
+	dryRunRecorder *command.Recorder
 }
 
 func NewController(
@@ -149,8 +275,25 @@ func NewController(
 	c := &Controller{
 		logger: logger,
 
-		currentState:     StateInitial,
-		currentNeighbors: newNeighborSet(),
+		switchoverTimeout: defaultSwitchoverTimeout,
+		shutdownPolicy:    ShutdownPolicyFault,
+		shutdownTimeout:   defaultShutdownTimeout,
+		reconcileTimeout:  defaultReconcileTimeout,
+
+		currentState:       StateInitial,
+		currentNeighbors:   newNeighborSet(),
+		neighborPriorities: make(map[neighbor]uint16),
+
+		neighborGTIDSequences:   make(map[neighbor]uint64),
+		neighborEpochs:          make(map[neighbor]uint64),
+		transitionConfirmations: make(map[Transition]uint),
+		switchoverRequestCh:     make(chan switchoverRequest),
+		maintenanceRequestCh:    make(chan maintenanceRequest),
+		transitionTable:         DefaultTransitionTable(),
+		fencingPolicy:           FencingPolicyRequired,
+
+		promotionGTIDWaitTimeout: defaultPromotionGTIDWaitTimeout,
+		promotionTimeoutPolicy:   PromotionTimeoutPolicyStayCandidate,
 
 		nftablesConnector:  nftables.NewDefaultConnector(logger),
 		mariaDBConnector:   mariadb.NewDefaultConnector(logger),
@@ -161,6 +304,32 @@ func NewController(
 	for _, cfg := range configs {
 		cfg(c)
 	}
+
+	if c.dryRunRecorder != nil {
+		// only the bgp advertisement and the read-only queries reach the outside.
+		c.systemdConnector = systemd.NewDryRunConnector(c.systemdConnector, c.dryRunRecorder)
+		c.nftablesConnector = nftables.NewDryRunConnector(c.nftablesConnector, c.dryRunRecorder)
+		c.mariaDBConnector = mariadb.NewDryRunConnector(c.mariaDBConnector, c.dryRunRecorder)
+		if c.fencer != nil {
+			c.fencer = fencing.NewDryRunFencer(c.dryRunRecorder)
+		}
+		if len(c.notifiers) != 0 {
+			c.notifiers = []notify.Notifier{notify.NewDryRunNotifier(c.dryRunRecorder)}
+		}
+	}
+
+	hookExecutor := hook.NewExecExecutor(logger)
+	if c.dryRunRecorder != nil {
+		hookExecutor = hook.NewDryRunExecutor(c.dryRunRecorder)
+	}
+	c.hookRunner = hook.NewRunner(logger, hookExecutor, c.hooks...)
+
+	if c.healthCheckRunner == nil {
+		// the same as the health check of the earlier versions.
+		c.healthCheckRunner = healthcheck.NewRunner(healthcheck.Check{
+			HealthChecker: healthcheck.NewSystemdUnitChecker(c.systemdConnector, mariadb.SystemdServiceName),
+		})
+	}
 	return c
 }
 
@@ -176,35 +345,103 @@ func (c *Controller) Start(
 	}
 	defer c.bgpServerConnector.Stop()
 
+	// the controller re-evaluates as soon as the routes or the peers are changed.
+	// the ticker is the safety net for the lost events, and drives the keep handlers.
+	events, err := c.bgpServerConnector.Watch(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the running MariaDB is adopted if the last state is still safe.
+	c.reconcile(ctx)
+
 	ticker := time.NewTicker(ctrlerLoopInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
-			c.forceTransitionToFault()
+			c.shutdown()
+			c.closeNotifiers()
 			return nil
+		case req := <-c.switchoverRequestCh:
+			req.result <- c.switchover()
+		case req := <-c.maintenanceRequestCh:
+			req.result <- c.setMaintenance(req.enter)
+		case ev := <-events:
+			c.logger.Debug("controller loop is triggered by bgp event", "type", ev.Type)
+			c.runControlLoop(ctx, true)
 		case <-ticker.C:
-			// random sleep to avoid global synchronization
-			time.Sleep(time.Second * time.Duration(rand.Intn(2)+1))
-
-			if err := c.preDecideNextStateHandler(); err != nil {
-				c.logger.Error("preDecideNextStateHandler", "error", err, "state", string(c.GetState()))
-				// we urgently transition to fault state
-				c.forceTransitionToFault()
-				continue
-			}
+			c.runControlLoop(ctx, false)
+		}
+	}
+}
 
-			nextState := c.decideNextState()
-			c.logger.Debug("controller decided next state", "next state", nextState)
+// runControlLoop runs an iteration of the controller loop.
+// the keep handlers run only on the ticker because they count the failures per loop.
+func (c *Controller) runControlLoop(ctx context.Context, triggeredByEvent bool) {
+	c.eventDriven = triggeredByEvent
+	defer func() { c.eventDriven = false }()
 
-			if err := c.onStateHandler(nextState); err != nil {
-				c.logger.Error("onStateHandler", "error", err, "next state", nextState)
-			}
+	nextState, err := c.observeAndDecideNextState()
+	if err != nil {
+		c.logger.Error("preDecideNextStateHandler", "error", err, "state", string(c.GetState()))
+		// we urgently transition to fault state
+		c.forceTransitionToFault()
+		return
+	}
+	// a transient observation (e.g. the missing route in a bgp flap) must not move the state.
+	nextState = c.confirmTransition(nextState, !triggeredByEvent)
+
+	if c.needsDesynchronization(nextState) {
+		// random sleep to avoid that the controllers become candidate at the same time.
+		// then we observe again because the other controller may become candidate while sleeping.
+		jitter := desynchronizationJitterMin + time.Duration(rand.Int63n(int64(desynchronizationJitterRange)))
+		c.logger.Debug("sleep before becoming candidate", "jitter", jitter)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(jitter):
+		}
+
+		// the observation after the sleep isn't counted again.
+		c.eventDriven = true
+		nextState, err = c.observeAndDecideNextState()
+		if err != nil {
+			c.logger.Error("preDecideNextStateHandler", "error", err, "state", string(c.GetState()))
+			c.forceTransitionToFault()
+			return
 		}
+		nextState = c.confirmTransition(nextState, false)
+		c.eventDriven = triggeredByEvent
+	}
+	c.logger.Debug("controller decided next state", "next state", nextState)
+
+	if triggeredByEvent && nextState == c.GetState() {
+		return
+	}
+
+	if err := c.onStateHandler(nextState); err != nil {
+		c.logger.Error("onStateHandler", "error", err, "next state", nextState)
 	}
 }
 
+// observeAndDecideNextState observes the neighbors and MariaDB, then determines the next state.
+func (c *Controller) observeAndDecideNextState() (State, error) {
+	if err := c.preDecideNextStateHandler(); err != nil {
+		return "", err
+	}
+
+	return c.decideNextState(), nil
+}
+
+// needsDesynchronization returns true if the controllers may take the transition at the same time.
+// the controllers that observe the same BGP event decide the same next state,
+// so the transition to candidate needs desynchronization to avoid the multi-candidate situation.
+func (c *Controller) needsDesynchronization(nextState State) bool {
+	return nextState == StateCandidate && c.GetState() != StateCandidate
+}
+
 // GetState returns the current state of the controller.
 func (c *Controller) GetState() State {
 	c.m.RLock()
@@ -216,31 +453,30 @@ func (c *Controller) GetState() State {
 // decideNextState determines next state that the controller should transition.
 func (c *Controller) decideNextState() State {
 	c.logger.Debug("decide next state", "current state", c.GetState())
-	if c.currentNeighbors.isNetworkParted() {
-		c.logger.Info("detected network partition", "neighbors", c.currentNeighbors.neighborAddresses())
+	def, ok := c.transitionTable[c.GetState()]
+	if !ok || def.Decide == nil {
+		c.logger.Error("the state has no decision in the transition table", "state", c.GetState())
 		return StateFault
 	}
 
-	switch c.GetState() {
-	case StateFault:
-		return c.decideNextStateOnFault()
-	case StateCandidate:
-		return c.decideNextStateOnCandidate()
-	case StatePrimary:
-		return c.decideNextStateOnPrimary()
-	case StateReplica:
-		return c.decideNextStateOnReplica()
-	case StateInitial:
-		// just initialized controller take this case.
+	if !def.IgnoresNetworkPartition && !c.hasQuorum() {
+		c.logger.Info("detected network partition. the quorum is lost.", "neighbors", c.currentNeighbors.neighborAddresses())
 		return StateFault
-	default:
-		panic("unreachable")
 	}
+
+	return def.Decide(c)
 }
 
 func (c *Controller) onStateHandler(nextState State) error {
-	if cannotTransitionTo(c.GetState(), nextState) {
-		panic("unreachable controller state was picked")
+	currentState := c.GetState()
+	if !c.transitionTable.CanTransition(currentState, nextState) {
+		// we urgently transition to fault state because the decision is broken.
+		c.forceTransitionToFault()
+		return fmt.Errorf("the transition table has no edge from %s to %s", currentState, nextState)
+	}
+	if guard := c.transitionTable[currentState].Transitions[nextState]; guard != nil && !guard(c) {
+		c.logger.Info("the guard rejected the transition. keep the current state.", "from", currentState, "to", nextState)
+		nextState = currentState
 	}
 	c.setState(nextState)
 
@@ -248,6 +484,8 @@ func (c *Controller) onStateHandler(nextState State) error {
 		if err := c.triggerRunOnStateKeeps(); err != nil {
 			c.logger.Error("failed to triggerRunOnStateKeeps. transition to fault state and exit", "error", err, "state", string(c.GetState()))
 			c.forceTransitionToFault()
+			// the alerts must be delivered before exiting.
+			c.closeNotifiers()
 			panic("urgently exit")
 		}
 
@@ -272,7 +510,26 @@ func (c *Controller) preDecideNextStateHandler() error {
 	}
 
 	currentNeighbors := newNeighborSet()
+	neighborPriorities := make(map[neighbor]uint16)
+	neighborGTIDSequences := make(map[neighbor]uint64)
+	neighborEpochs := make(map[neighbor]uint64)
 	for _, route := range routes {
+		if len(route.LargeCommunities) != 0 {
+			for _, comm := range route.LargeCommunities {
+				if seq, ok := parseGTIDLargeCommunity(comm); ok {
+					neighborGTIDSequences[neighbor(route.Prefix.Addr().String())] = seq
+				}
+				if epoch, ok := parseEpochLargeCommunity(comm); ok {
+					neighborEpochs[neighbor(route.Prefix.Addr().String())] = epoch
+				}
+			}
+			continue
+		}
+		if priority, ok := parsePriorityCommunity(route.Community); ok {
+			neighborPriorities[neighbor(route.Prefix.Addr().String())] = priority
+			continue
+		}
+
 		state, ok := bgpCommunityToState[route.Community]
 		if !ok {
 			// ignore route with unknown community
@@ -296,21 +553,42 @@ func (c *Controller) preDecideNextStateHandler() error {
 			currentNeighbors[state] = append(currentNeighbors[state], neighbor(addr))
 		}
 	}
+	for _, neighbors := range currentNeighbors {
+		// the order of the routes is not stable, but the decisions must be deterministic.
+		slices.SortFunc(neighbors, func(a, b neighbor) int { return compareAddress(string(a), string(b)) })
+	}
 	c.currentNeighbors = currentNeighbors
+	c.neighborPriorities = neighborPriorities
+	c.neighborGTIDSequences = neighborGTIDSequences
+	c.neighborEpochs = neighborEpochs
+	c.rejectStalePrimaries()
+	if c.currentNeighbors.primaryNodeExists() {
+		c.lastPrimaryNeighbor = c.currentNeighbors[StatePrimary][0]
+	} else {
+		c.forgetDemotedPrimary()
+	}
 
 	// to avoiding unnecessary calculation, we checks the logger's level.
 	if prevNeighbors.different(c.currentNeighbors) {
 		addrs := c.currentNeighbors.neighborAddresses()
 		c.logger.Info("neighbor set is updated", "addresses", addrs)
+		c.notify(notify.Event{Type: notify.EventNeighborsChanged, Message: addrs})
 	}
+	c.detectDualPrimary()
 
 	c.currentMariaDBHealth = c.checkMariaDBHealth()
+	c.observeGTIDSequence()
 
 	// judging "not ready" to primary when mariadb is not healthy
 	if c.currentMariaDBHealth == dbHealthCheckResultNG {
 		c.readyToPrimary = readytoPrimaryJudgeNG
 		return nil
 	}
+	// the judgement may wait for the relay log, so that is done only when it's needed.
+	if c.GetState() != StateCandidate {
+		c.readyToPrimary = readytoPrimaryJudgeNG
+		return nil
+	}
 	c.readyToPrimary = c.readyToBePromotedToPrimary()
 
 	return nil
@@ -318,6 +596,11 @@ func (c *Controller) preDecideNextStateHandler() error {
 
 // setState sets the given state as the current state of the controller.
 func (c *Controller) setState(nextState State) {
+	c.setStateWithReason(nextState, journalReasonDecided)
+}
+
+// setStateWithReason sets the state and records the transition with the reason to the journal.
+func (c *Controller) setStateWithReason(nextState State, reason string) {
 	c.prevState = c.GetState()
 	{
 		c.m.Lock()
@@ -329,11 +612,18 @@ func (c *Controller) setState(nextState State) {
 		c.logger.Debug("controller transitions the state(unchanged)", "from", c.prevState, "to", nextState)
 	} else {
 		c.logger.Info("controller transitions the state(changed)", "from", c.prevState, "to", nextState)
+		// the yielding is counted in each state.
+		c.priorityYieldCount = 0
+		c.resetPendingTransition()
+		if err := c.recordJournal(c.prevState, nextState, reason); err != nil {
+			c.logger.Warn("failed to record the journal", "error", err, "from", c.prevState, "to", nextState)
+		}
+		c.notify(notify.Event{Type: notify.EventStateChanged, From: string(c.prevState), To: string(nextState), Reason: reason})
 	}
 
 	// modify state metric(s)
 	dbControllerStateTransitionCounterVec.WithLabelValues(string(nextState)).Inc()
-	for s := range controllerAllStates {
+	for s := range c.transitionTable {
 		// clear flag of all state
 		dbControllerStateGaugeVec.WithLabelValues(string(s)).Set(0)
 	}
@@ -342,31 +632,41 @@ func (c *Controller) setState(nextState State) {
 }
 
 // triggerRunOnStateChanges triggers the state handler if the previous state is not the current state.
+// the exit handler of the previous state runs before the entry handler of the current state,
+// and the lifecycle hooks run around them.
 func (c *Controller) triggerRunOnStateChanges() error {
-	switch c.GetState() {
-	case StatePrimary:
-		return c.triggerRunOnStateChangesToPrimary()
-	case StateFault:
-		return c.triggerRunOnStateChangesToFault()
-	case StateCandidate:
-		return c.triggerRunOnStateChangesToCandidate()
-	case StateReplica:
-		return c.triggerRunOnStateChangesToReplica()
-	}
-
-	panic("unreachable")
+	from, to := c.getPreviousState(), c.GetState()
+	if err := c.runPreTransitionHooks(from, to); err != nil {
+		return err
+	}
+
+	if prev, ok := c.transitionTable[from]; ok && prev.OnExit != nil {
+		if err := prev.OnExit(c); err != nil {
+			return err
+		}
+	}
+
+	def, ok := c.transitionTable[to]
+	if !ok {
+		return fmt.Errorf("the state %s is not defined in the transition table", to)
+	}
+	if def.OnEntry != nil {
+		if err := def.OnEntry(c); err != nil {
+			return err
+		}
+	}
+
+	return c.runPostTransitionHooks(from, to)
 }
 
 // triggerRunOnStateKeeps triggers the state handler if the previous state is same as the current state.
 func (c *Controller) triggerRunOnStateKeeps() error {
-	switch c.GetState() {
-	case StatePrimary:
-		return c.triggerRunOnStateKeepsPrimary()
-	case StateReplica:
-		return c.triggerRunOnStateKeepsReplica()
+	def, ok := c.transitionTable[c.GetState()]
+	if !ok || def.OnKeep == nil {
+		return nil
 	}
 
-	return nil
+	return def.OnKeep(c)
 }
 
 // advertiseSelfNetIFAddress updates the configuration of the advertising route.
@@ -387,7 +687,23 @@ func (c *Controller) advertiseSelfNetIFAddress() error {
 		Prefix:    prefix,
 		Community: comm,
 	}
-	return c.bgpServerConnector.AddPath(route)
+	if c.priority != 0 {
+		route.AdditionalCommunities = []bgpserver.Community{priorityCommunity(c.priority)}
+	}
+	// the gtid sequence is meaningful only while MariaDB is running as a replica.
+	state := c.GetState()
+	if c.gtidSequenceKnown && (state == StateReplica || state == StateCandidate) {
+		route.LargeCommunities = []bgpserver.LargeCommunity{gtidLargeCommunity(c.gtidSequence)}
+	}
+	if state == StatePrimary && c.epoch != 0 {
+		route.LargeCommunities = []bgpserver.LargeCommunity{epochLargeCommunity(c.epoch)}
+	}
+	if err := c.bgpServerConnector.AddPath(route); err != nil {
+		return err
+	}
+
+	c.advertisedGTIDSequence = c.gtidSequence
+	return nil
 }
 
 // forceTransitionToFault set state to fault and triggers fault handler
@@ -397,7 +713,7 @@ func (c *Controller) forceTransitionToFault() {
 		return
 	}
 
-	c.setState(StateFault)
+	c.setStateWithReason(StateFault, journalReasonForced)
 	if err := c.triggerRunOnStateChanges(); err != nil {
 		c.logger.Info("failed to TriggerRunOnStateChanges while going to fault. Ignore errors.", "error", err)
 	}
@@ -419,34 +735,43 @@ func (c *Controller) getPreviousState() State {
 }
 
 // checkMariaDBHealth checks whether the MariaDB server is healthy or not.
+// the result is aggregated from the health checks, see healthcheck.Runner.
+// the failures aren't counted in the loop triggered by the bgp event.
 func (c *Controller) checkMariaDBHealth() dbHealthCheckResult {
-	if err := c.systemdConnector.CheckServiceStatus(mariadb.SystemdServiceName); err != nil {
-		c.logger.Debug("'systemctl status mariadb' exit with returning error", "error", err)
+	run := c.healthCheckRunner.Run
+	if c.eventDriven {
+		run = c.healthCheckRunner.RunUncounted
+	}
+
+	if !run(context.Background()) {
+		for _, res := range c.healthCheckRunner.Results() {
+			if !res.Healthy {
+				c.logger.Debug("health check failed", "check", res.Name, "error", res.LastError, "failures", res.ConsecutiveFailures)
+			}
+		}
 		return dbHealthCheckResultNG
 	}
 
 	return dbHealthCheckResultOK
 }
 
-// readyToBePromotedToPrimary returns true when the controller satisfies the conditions to be promoted to primary state.
-func (c *Controller) readyToBePromotedToPrimary() readyToPrimaryJudge {
-	status, err := c.mariaDBConnector.ShowReplicationStatus()
-	if err != nil {
-		c.logger.Debug("failed to show replication status", "error", err)
-		return readytoPrimaryJudgeNG
-	}
-
-	readMasterLogPos, ok := status[mariadb.ReplicationStatusReadMasterLogPos]
-	if !ok {
-		return readytoPrimaryJudgeOK
-	}
+// DryRun returns true if the controller runs in the dry-run mode.
+func (c *Controller) DryRun() bool {
+	return c.dryRunRecorder != nil
+}
 
-	if readMasterLogPos == status[mariadb.ReplicationStatusExecMasterLogPos] &&
-		status[mariadb.ReplicationStatusMasterLogFile] == status[mariadb.ReplicationStatusRelayMasterLogFile] {
-		return readytoPrimaryJudgeOK
+// DryRunCommands returns the commands that the controller would have run in the dry-run mode.
+// the function returns the last `limit` commands if limit is positive.
+func (c *Controller) DryRunCommands(limit int) []command.RecordedCommand {
+	if c.dryRunRecorder == nil {
+		return make([]command.RecordedCommand, 0)
 	}
+	return c.dryRunRecorder.Commands(limit)
+}
 
-	return readytoPrimaryJudgeNG
+// HealthCheckResults returns the latest result of each health check.
+func (c *Controller) HealthCheckResults() []healthcheck.Result {
+	return c.healthCheckRunner.Results()
 }
 
 // syncReadOnlyVariable updates the read_only variable to the given expected value.
@@ -482,25 +807,3 @@ func (c *Controller) startMariaDBService() error {
 
 	return nil
 }
-
-// cannotTransitionTo checks whether the state machine doesn't have the edge from current to next.
-func cannotTransitionTo(
-	currentState State,
-	nextState State,
-) bool {
-	switch currentState {
-	case StateFault:
-		return nextState == StatePrimary
-	case StateCandidate:
-		return nextState == StateReplica
-	case StatePrimary:
-		return nextState == StateCandidate || nextState == StateReplica
-	case StateReplica:
-		return nextState == StatePrimary
-	case StateInitial:
-		return nextState != StateFault
-	default:
-		// unreachable
-		return true
-	}
-}
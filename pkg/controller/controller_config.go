@@ -15,9 +15,16 @@
 package controller
 
 import (
+	"time"
+
 	"github.com/sakura-internet/distributed-mariadb-controller/pkg/bgpserver"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/command"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/fencing"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/healthcheck"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/hook"
 	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
 	"github.com/sakura-internet/distributed-mariadb-controller/pkg/nftables"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/notify"
 	"github.com/sakura-internet/distributed-mariadb-controller/pkg/systemd"
 )
 
@@ -66,6 +73,129 @@ func WithDBAclChainName(dbAclChainName string) ControllerConfig {
 	}
 }
 
+func WithSwitchoverTimeout(switchoverTimeout time.Duration) ControllerConfig {
+	return func(c *Controller) {
+		c.switchoverTimeout = switchoverTimeout
+	}
+}
+
+// WithJournalFilePath generates a config that sets the journal file of the state transitions.
+// the empty path disables the journal.
+func WithJournalFilePath(journalFilePath string) ControllerConfig {
+	return func(c *Controller) {
+		c.journalFilePath = journalFilePath
+	}
+}
+
+// WithAdoptRunningMariaDB generates a config that enables the startup reconciliation.
+func WithAdoptRunningMariaDB(adopt bool, timeout time.Duration) ControllerConfig {
+	return func(c *Controller) {
+		c.adoptRunningMariaDB = adopt
+		c.reconcileTimeout = timeout
+	}
+}
+
+// WithPriority generates a config that sets the promotion priority of Controller.
+// the node with the higher priority is preferred in the election. zero means no preference.
+func WithPriority(priority uint16) ControllerConfig {
+	return func(c *Controller) {
+		c.priority = priority
+	}
+}
+
+// WithFailback generates a config that enables the automatic failback to the higher-priority replica.
+// the failback runs after the replica stays for the stabilization period and only in the given windows.
+func WithFailback(stabilizationPeriod time.Duration, windows []FailbackWindow) ControllerConfig {
+	return func(c *Controller) {
+		c.failbackEnabled = true
+		c.failbackStabilizationPeriod = stabilizationPeriod
+		c.failbackWindows = windows
+	}
+}
+
+// WithPromotionGate generates a config that sets the conditions of the promotion.
+// maxLag is the maximum replication lag (zero disables the limit), and
+// gtidWaitTimeout is the time limit for waiting the relay log is applied.
+func WithPromotionGate(maxLag time.Duration, gtidWaitTimeout time.Duration, policy PromotionTimeoutPolicy) ControllerConfig {
+	return func(c *Controller) {
+		c.promotionMaxLag = maxLag
+		c.promotionGTIDWaitTimeout = gtidWaitTimeout
+		c.promotionTimeoutPolicy = policy
+	}
+}
+
+// WithQuorum generates a config that requires the strict majority of the members to be visible.
+// the controller steps down to fault state when the quorum is lost, and can't be promoted without that.
+func WithQuorum(members []QuorumMember) ControllerConfig {
+	return func(c *Controller) {
+		c.quorumMembers = members
+	}
+}
+
+// WithTransitionConfirmations generates a config that dampens the transitions.
+// each transition in the map is taken after it is observed in the given number of consecutive loops.
+// the transitions to fault caused by the conflicting writers or the unhealthy MariaDB are always immediate.
+func WithTransitionConfirmations(confirmations map[Transition]uint) ControllerConfig {
+	return func(c *Controller) {
+		c.transitionConfirmations = confirmations
+	}
+}
+
+// WithDryRun generates a config that makes the controller observe-only.
+// the controller runs the full loop and advertises the decided state over BGP,
+// but the side effects on systemd, nftables, MariaDB and the fencer are only recorded to the recorder.
+func WithDryRun(recorder *command.Recorder) ControllerConfig {
+	return func(c *Controller) {
+		c.dryRunRecorder = recorder
+	}
+}
+
+// WithHealthChecks generates a config that replaces the health checks of MariaDB.
+// MariaDB is regarded as healthy only if all checks are healthy.
+func WithHealthChecks(checks ...healthcheck.Check) ControllerConfig {
+	return func(c *Controller) {
+		c.healthCheckRunner = healthcheck.NewRunner(checks...)
+	}
+}
+
+// WithTransitionTable generates a config that replaces the state machine of Controller.
+// use DefaultTransitionTable() as the base of the custom table.
+func WithTransitionTable(table TransitionTable) ControllerConfig {
+	return func(c *Controller) {
+		c.transitionTable = table
+	}
+}
+
+// WithFencer generates a config that sets the fencing.Fencer into Controller.
+func WithFencer(fencer fencing.Fencer) ControllerConfig {
+	return func(c *Controller) {
+		c.fencer = fencer
+	}
+}
+
+// WithConnectionDraining generates a config that makes the demoted primary drain the connections.
+// the primary stops accepting the new connections, waits for the in-flight transactions within the grace period,
+// and then kills the remaining client sessions.
+func WithConnectionDraining(gracePeriod time.Duration) ControllerConfig {
+	return func(c *Controller) {
+		c.drainGracePeriod = gracePeriod
+	}
+}
+
+// WithNotifiers generates a config that sets the notify.Notifier(s) into Controller.
+// the events are sent to all notifiers.
+func WithNotifiers(notifiers ...notify.Notifier) ControllerConfig {
+	return func(c *Controller) {
+		c.notifiers = notifiers
+	}
+}
+
+func WithFencingPolicy(policy FencingPolicy) ControllerConfig {
+	return func(c *Controller) {
+		c.fencingPolicy = policy
+	}
+}
+
 // WithSystemdConnector generates a config that sets the systemd.Connector into Controller.
 func WithSystemdConnector(connector systemd.Connector) ControllerConfig {
 	return func(c *Controller) {
@@ -93,3 +223,32 @@ func WithBgpServerConnector(connector bgpserver.Connector) ControllerConfig {
 		c.bgpServerConnector = connector
 	}
 }
+
+// WithReplicaReadTraffic generates a config that makes the replica accept the read-only clients.
+// port is the port that accepts the read traffic (zero means the serving port),
+// sources are the allowed addresses or prefixes (empty means any source), and
+// maxLag is the maximum replication lag that the replica is regarded as readable (zero disables the limit).
+func WithReplicaReadTraffic(port uint16, sources []string, maxLag time.Duration) ControllerConfig {
+	return func(c *Controller) {
+		c.replicaReadEnabled = true
+		c.replicaReadPort = port
+		c.replicaReadSources = sources
+		c.replicaReadMaxLag = maxLag
+	}
+}
+
+// WithShutdownPolicy generates a config that sets how the primary controller stops on the stop signal.
+// timeout is the time limit of the handoff, including waiting for the new primary.
+func WithShutdownPolicy(policy ShutdownPolicy, timeout time.Duration) ControllerConfig {
+	return func(c *Controller) {
+		c.shutdownPolicy = policy
+		c.shutdownTimeout = timeout
+	}
+}
+
+// WithHooks generates a config that runs the lifecycle hooks around the state transitions.
+func WithHooks(hooks ...hook.Hook) ControllerConfig {
+	return func(c *Controller) {
+		c.hooks = hooks
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/bgpserver"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/healthcheck"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/systemd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStart_BgpEventTriggersLoop(t *testing.T) {
+	c := _newFakeController()
+	fakeBgpServerConnector := c.bgpServerConnector.(*bgpserver.FakeBgpServerConnector)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// the ticker never fires in this test.
+		assert.NoError(t, c.Start(ctx, time.Hour))
+	}()
+
+	fakeBgpServerConnector.Events <- bgpserver.Event{Type: bgpserver.EventTypePeer}
+	assert.Eventually(t, func() bool {
+		return c.GetState() == StateFault
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func TestNeedsDesynchronization(t *testing.T) {
+	c := _newFakeController()
+
+	c.setState(StateFault)
+	assert.True(t, c.needsDesynchronization(StateCandidate))
+	assert.False(t, c.needsDesynchronization(StateReplica))
+
+	c.setState(StateCandidate)
+	assert.False(t, c.needsDesynchronization(StateCandidate))
+	assert.False(t, c.needsDesynchronization(StatePrimary))
+}
+
+func TestCheckMariaDBHealth_WithHealthChecks(t *testing.T) {
+	c := _newFakeController()
+	fakeMariaDBConn := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	WithHealthChecks(
+		healthcheck.Check{HealthChecker: healthcheck.NewSystemdUnitChecker(c.systemdConnector, mariadb.SystemdServiceName)},
+		healthcheck.Check{HealthChecker: healthcheck.NewInnoDBChecker(fakeMariaDBConn)},
+	)(c)
+
+	assert.Equal(t, dbHealthCheckResultOK, c.checkMariaDBHealth())
+
+	fakeMariaDBConn.InnoDBSupport = "NO"
+	assert.Equal(t, dbHealthCheckResultNG, c.checkMariaDBHealth())
+
+	results := c.HealthCheckResults()
+	assert.Len(t, results, 2)
+	assert.True(t, results[0].Healthy)
+	assert.False(t, results[1].Healthy)
+	assert.Equal(t, "innodb", results[1].Name)
+}
+
+func TestCheckMariaDBHealth_DefaultIsSystemd(t *testing.T) {
+	c := _newFakeController()
+
+	assert.Equal(t, dbHealthCheckResultOK, c.checkMariaDBHealth())
+	_, called := c.systemdConnector.(*systemd.FakeSystemdConnector).Timestamp["CheckServiceStatus"]
+	assert.True(t, called)
+	assert.Equal(t, "systemd", c.HealthCheckResults()[0].Name)
+}
+
+func TestRunControlLoop_EventDoesNotAdvanceCounters(t *testing.T) {
+	c := _newFakeController()
+	WithPriority(100)(c)
+	fakeMariaDBConn := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	WithHealthChecks(healthcheck.Check{HealthChecker: healthcheck.NewInnoDBChecker(fakeMariaDBConn), FailureThreshold: 2})(c)
+	c.setState(StateFault)
+
+	fakeBgpServerConnector := c.bgpServerConnector.(*bgpserver.FakeBgpServerConnector)
+	fakeBgpServerConnector.Routes = []bgpserver.Route{
+		{Prefix: netip.MustParsePrefix("10.0.0.2/32"), Community: bgpCommunityFault},
+		{Prefix: netip.MustParsePrefix("10.0.0.2/32"), Community: priorityCommunity(200)},
+	}
+	fakeMariaDBConn.InnoDBSupport = "NO"
+
+	// a flap fires many events in a moment.
+	for i := 0; i < priorityYieldThreshold*2; i++ {
+		c.runControlLoop(context.Background(), true)
+	}
+	assert.Equal(t, StateFault, c.GetState())
+	assert.Equal(t, uint(0), c.priorityYieldCount)
+	assert.Equal(t, uint(0), c.HealthCheckResults()[0].ConsecutiveFailures)
+
+	c.runControlLoop(context.Background(), false)
+	assert.Equal(t, uint(1), c.priorityYieldCount)
+	assert.Equal(t, uint(1), c.HealthCheckResults()[0].ConsecutiveFailures)
+	assert.False(t, c.eventDriven)
+}
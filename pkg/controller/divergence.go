@@ -0,0 +1,106 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+)
+
+// ErrDiverged is returned when the local MariaDB has the transactions that the primary never saw.
+var ErrDiverged = errors.New("the local MariaDB has diverged from the primary")
+
+// Divergence describes the errant transactions that block the replication from the primary.
+// that is typically left by the previous primary that lost writes in the split-brain.
+type Divergence struct {
+	Primary     string    `json:"primary"`
+	LocalGTID   string    `json:"local_gtid"`
+	PrimaryGTID string    `json:"primary_gtid"`
+	ErrantGTID  string    `json:"errant_gtid"`
+	DetectedAt  time.Time `json:"detected_at"`
+}
+
+// checkDivergenceFrom compares the local gtid_binlog_pos with the one of the primary.
+// the function records the divergence and returns ErrDiverged if the local has errant transactions.
+func (c *Controller) checkDivergenceFrom(primary neighbor) error {
+	local, err := c.mariaDBConnector.ShowGTIDBinlogPos()
+	if err != nil {
+		return err
+	}
+
+	remote := mariadb.RemoteInstance{
+		Host:     string(primary),
+		Port:     c.dbReplicaSourcePort,
+		User:     c.dbReplicaUserName,
+		Password: c.dbReplicaPassword,
+	}
+	primaryPos, err := c.mariaDBConnector.ShowRemoteGTIDBinlogPos(remote)
+	if err != nil {
+		return err
+	}
+
+	errant, err := local.ErrantAgainst(primaryPos, func(gtid mariadb.GTID) (bool, error) {
+		return c.mariaDBConnector.RemoteBinlogContainsGTID(remote, gtid)
+	})
+	if err != nil {
+		return err
+	}
+	if len(errant) == 0 {
+		return nil
+	}
+
+	d := &Divergence{
+		Primary:     string(primary),
+		LocalGTID:   local.String(),
+		PrimaryGTID: primaryPos.String(),
+		ErrantGTID:  errant.String(),
+		DetectedAt:  time.Now(),
+	}
+	c.logger.Error("detected errant transactions. refuse to replicate from the primary.",
+		"primary", d.Primary, "local gtid", d.LocalGTID, "primary gtid", d.PrimaryGTID, "errant gtid", d.ErrantGTID)
+	c.setDivergence(d)
+
+	return fmt.Errorf("%w: errant gtid %s", ErrDiverged, d.ErrantGTID)
+}
+
+// GetDivergence returns the detected divergence.
+// the function returns nil if the controller hasn't diverged.
+func (c *Controller) GetDivergence() *Divergence {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	return c.divergence
+}
+
+// ClearDivergence allows the controller to replicate from the primary again.
+// the operator calls this after resolving the errant transactions by hand.
+func (c *Controller) ClearDivergence() {
+	c.setDivergence(nil)
+}
+
+func (c *Controller) setDivergence(d *Divergence) {
+	c.m.Lock()
+	c.divergence = d
+	c.m.Unlock()
+
+	if d == nil {
+		dbControllerDivergedGauge.Set(0)
+	} else {
+		dbControllerDivergedGauge.Set(1)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTriggerRunOnStateChangesToReplica_Diverged(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateFault)
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+
+	fakeMariaDBConn := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConn.GTIDBinlogPos, _ = mariadb.ParseGTIDSet("0-1-105")
+	fakeMariaDBConn.RemoteGTIDBinlogPos["10.0.0.2"], _ = mariadb.ParseGTIDSet("0-2-100")
+
+	err := c.triggerRunOnStateChangesToReplica()
+	assert.True(t, errors.Is(err, ErrDiverged))
+
+	_, called := fakeMariaDBConn.Timestamp["ChangeMasterTo"]
+	assert.False(t, called, "must not replicate from the primary")
+
+	d := c.GetDivergence()
+	if assert.NotNil(t, d) {
+		assert.Equal(t, "10.0.0.2", d.Primary)
+		assert.Equal(t, "0-1-105", d.ErrantGTID)
+	}
+
+	// the diverged controller keeps fault state even if the primary exists.
+	c.setState(StateFault)
+	assert.Equal(t, StateFault, c.decideNextStateOnFault())
+
+	c.ClearDivergence()
+	assert.Nil(t, c.GetDivergence())
+	assert.Equal(t, StateReplica, c.decideNextStateOnFault())
+}
+
+func TestTriggerRunOnStateChangesToReplica_BehindPrimary(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateFault)
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+
+	fakeMariaDBConn := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConn.GTIDBinlogPos, _ = mariadb.ParseGTIDSet("0-1-100")
+	fakeMariaDBConn.RemoteGTIDBinlogPos["10.0.0.2"], _ = mariadb.ParseGTIDSet("0-2-110")
+
+	assert.NoError(t, c.triggerRunOnStateChangesToReplica())
+	assert.Nil(t, c.GetDivergence())
+}
+
+func TestTriggerRunOnStateChangesToReplica_LostWriteBehindPrimary(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateFault)
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+
+	// the old primary wrote 0-1-101 that was never replicated,
+	// and the new primary has written its own transactions since 0-2-101.
+	fakeMariaDBConn := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConn.GTIDBinlogPos, _ = mariadb.ParseGTIDSet("0-1-101")
+	fakeMariaDBConn.RemoteGTIDBinlogPos["10.0.0.2"], _ = mariadb.ParseGTIDSet("0-2-150")
+	fakeMariaDBConn.RemoteGTIDHistory["10.0.0.2"] = []mariadb.GTID{
+		{DomainID: 0, ServerID: 1, SeqNo: 100},
+		{DomainID: 0, ServerID: 2, SeqNo: 101},
+	}
+
+	err := c.triggerRunOnStateChangesToReplica()
+	assert.True(t, errors.Is(err, ErrDiverged))
+	if d := c.GetDivergence(); assert.NotNil(t, d) {
+		assert.Equal(t, "0-1-101", d.ErrantGTID)
+	}
+}
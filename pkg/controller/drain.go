@@ -0,0 +1,135 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/nftables"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/notify"
+)
+
+const (
+	// drainCheckInterval is the interval of checking the in-flight transactions while draining.
+	drainCheckInterval = 500 * time.Millisecond
+)
+
+// drainConnections stops accepting the new connections and lets the in-flight transactions finish.
+// the client sessions that remain after the grace period are killed and reported.
+// the function does nothing if the draining is disabled.
+func (c *Controller) drainConnections() error {
+	if c.drainGracePeriod == 0 {
+		return nil
+	}
+
+	if err := c.rejectNewDatabaseServiceConnections(); err != nil {
+		return err
+	}
+
+	c.logger.Info("start draining the connections", "grace period", c.drainGracePeriod)
+	deadline := time.Now().Add(c.drainGracePeriod)
+	for {
+		sessions, err := c.clientSessions()
+		if err != nil {
+			return err
+		}
+
+		busy := slices.ContainsFunc(sessions, mariadb.Process.IsBusy)
+		if !busy || !time.Now().Before(deadline) {
+			// the idle sessions are killed as well, they would be cut off by the reject rule anyway.
+			c.killClientSessions(sessions)
+			return nil
+		}
+		time.Sleep(min(drainCheckInterval, time.Until(deadline)))
+	}
+}
+
+// shouldDrainOnDemotion returns true if the primary is stepping down with the healthy MariaDB.
+// the transactions must not be accepted while another primary exists.
+func (c *Controller) shouldDrainOnDemotion() bool {
+	return c.getPreviousState() == StatePrimary &&
+		c.currentMariaDBHealth == dbHealthCheckResultOK &&
+		!c.currentNeighbors.primaryNodeExists()
+}
+
+// rejectNewDatabaseServiceConnections sets the rules that reject the new connections
+// but keep the established ones.
+func (c *Controller) rejectNewDatabaseServiceConnections() error {
+	if err := c.nftablesConnector.FlushChain(c.dbAclChainName); err != nil {
+		return err
+	}
+
+	rejectMatches := []nftables.Match{
+		nftables.IFNameMatch(c.globalInterfaceName),
+		nftables.TCPDstPortMatch(c.dbServingPort),
+		nftables.CTStateMatch("new"),
+	}
+	if err := c.nftablesConnector.AddRule(c.dbAclChainName, rejectMatches, nftables.RejectStatement()); err != nil {
+		return err
+	}
+
+	acceptMatches := []nftables.Match{
+		nftables.IFNameMatch(c.globalInterfaceName),
+		nftables.TCPDstPortMatch(c.dbServingPort),
+	}
+	if err := c.nftablesConnector.AddRule(c.dbAclChainName, acceptMatches, nftables.AcceptStatement()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// clientSessions returns the sessions connected from the clients.
+func (c *Controller) clientSessions() ([]mariadb.Process, error) {
+	processes, err := c.mariaDBConnector.ShowProcessList()
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(processes, func(p mariadb.Process) bool { return !p.IsClientSession() }), nil
+}
+
+// killClientSessions kills the sessions and reports them.
+// the session that has already gone is ignored.
+func (c *Controller) killClientSessions(sessions []mariadb.Process) {
+	killed := make([]string, 0, len(sessions))
+	busy := 0
+	for _, s := range sessions {
+		if err := c.mariaDBConnector.KillConnection(s.ID); err != nil {
+			c.logger.Debug("failed to kill the session", "session", s.String(), "error", err)
+			continue
+		}
+		killed = append(killed, s.String())
+		if s.IsBusy() {
+			busy++
+		}
+	}
+
+	if len(killed) == 0 {
+		c.logger.Info("drained the connections")
+		return
+	}
+
+	dbControllerDrainKilledSessionsCounter.Add(float64(len(killed)))
+	c.logger.Warn("killed the remaining sessions after draining", "sessions", killed, "busy", busy)
+	c.notify(notify.Event{
+		Type:    notify.EventSessionsKilled,
+		Message: fmt.Sprintf("%d sessions (%d busy): %s", len(killed), busy, strings.Join(killed, ",")),
+	})
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/nftables"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/notify"
+	"github.com/stretchr/testify/assert"
+)
+
+func _newFakeProcesses() []mariadb.Process {
+	return []mariadb.Process{
+		{ID: 5, User: "system user", Command: "Daemon"},
+		{ID: 12, User: "repl", Host: "10.0.0.2:41234", Command: "Binlog Dump GTID"},
+		{ID: 20, User: "app", Host: "192.0.2.10:50000", DB: "shop", Command: "Sleep"},
+	}
+}
+
+func TestDrainConnections_Disabled(t *testing.T) {
+	c := _newFakeController()
+
+	assert.NoError(t, c.drainConnections())
+	fakeNftablesConnector := c.nftablesConnector.(*nftables.FakeNftablesConnector)
+	_, flushed := fakeNftablesConnector.Timestamp["FlushChain"]
+	assert.False(t, flushed)
+}
+
+func TestDrainConnections_Idle(t *testing.T) {
+	c := _newFakeController()
+	WithConnectionDraining(time.Minute)(c)
+	fakeNotifier := _newFakeNotifier(c)
+	fakeMariaDBConnector := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConnector.Processes = _newFakeProcesses()
+
+	// no transaction is in flight, so the grace period isn't waited.
+	start := time.Now()
+	assert.NoError(t, c.drainConnections())
+	assert.Less(t, time.Since(start), time.Second)
+
+	// the new connections are rejected but the established ones are kept.
+	fakeNftablesConnector := c.nftablesConnector.(*nftables.FakeNftablesConnector)
+	assert.Equal(t, []string{
+		"iifname dummy-global-interface-name tcp dport 3306 ct state new reject",
+		"iifname dummy-global-interface-name tcp dport 3306 accept",
+	}, fakeNftablesConnector.Rules["dummy-chain-name"])
+
+	// the replication to the replica survives.
+	assert.Equal(t, []uint64{20}, fakeMariaDBConnector.Killed)
+	assert.Equal(t, []notify.EventType{notify.EventSessionsKilled}, fakeNotifier.EventTypes())
+}
+
+func TestDrainConnections_BusyAfterGracePeriod(t *testing.T) {
+	c := _newFakeController()
+	WithConnectionDraining(50 * time.Millisecond)(c)
+	fakeMariaDBConnector := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConnector.Processes = append(_newFakeProcesses(),
+		mariadb.Process{ID: 21, User: "app", Host: "192.0.2.10:50001", Command: "Query"},
+		mariadb.Process{ID: 22, User: "app", Host: "192.0.2.10:50002", Command: "Sleep", InTransaction: true},
+	)
+
+	start := time.Now()
+	assert.NoError(t, c.drainConnections())
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, []uint64{20, 21, 22}, fakeMariaDBConnector.Killed)
+}
+
+func TestTriggerRunOnStateChangesToFault_DrainsDemotedPrimary(t *testing.T) {
+	c := _newFakeController()
+	WithConnectionDraining(time.Minute)(c)
+	fakeMariaDBConnector := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConnector.Processes = _newFakeProcesses()
+
+	c.setState(StatePrimary)
+	c.setState(StateFault)
+	assert.NoError(t, c.triggerRunOnStateChangesToFault())
+	assert.Equal(t, []uint64{20}, fakeMariaDBConnector.Killed)
+}
+
+func TestTriggerRunOnStateChangesToFault_NoDrainWithAnotherPrimary(t *testing.T) {
+	c := _newFakeController()
+	WithConnectionDraining(time.Minute)(c)
+	fakeMariaDBConnector := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConnector.Processes = _newFakeProcesses()
+
+	// dual primary. the transactions must not be accepted anymore.
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+	c.setState(StatePrimary)
+	c.setState(StateFault)
+	assert.NoError(t, c.triggerRunOnStateChangesToFault())
+	assert.Empty(t, fakeMariaDBConnector.Killed)
+
+	// the replica has nothing to drain.
+	c.currentNeighbors[StatePrimary] = []neighbor{}
+	c.setState(StateReplica)
+	c.setState(StateFault)
+	assert.NoError(t, c.triggerRunOnStateChangesToFault())
+	assert.Empty(t, fakeMariaDBConnector.Killed)
+}
+
+func TestSwitchover_DrainsAndUndrainsOnAbort(t *testing.T) {
+	c := _newFakeController()
+	WithConnectionDraining(time.Minute)(c)
+	WithSwitchoverTimeout(10 * time.Millisecond)(c)
+	c.setState(StatePrimary)
+	c.currentNeighbors[StateReplica] = []neighbor{"10.0.0.2"}
+
+	fakeMariaDBConnector := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConnector.Processes = _newFakeProcesses()
+	fakeMariaDBConnector.GTIDBinlogPos, _ = mariadb.ParseGTIDSet("0-1-100")
+	fakeMariaDBConnector.RemoteGTIDSlavePos["10.0.0.2"], _ = mariadb.ParseGTIDSet("0-1-90")
+
+	assert.ErrorIs(t, c.switchover(), ErrSwitchoverTimeout)
+	assert.Equal(t, []uint64{20}, fakeMariaDBConnector.Killed)
+
+	// the primary accepts the new connections again.
+	fakeNftablesConnector := c.nftablesConnector.(*nftables.FakeNftablesConnector)
+	assert.Equal(t, []string{
+		"iifname dummy-global-interface-name tcp dport 3306 accept",
+	}, fakeNftablesConnector.Rules["dummy-chain-name"])
+	assert.False(t, fakeMariaDBConnector.ReadOnlyVariable)
+	assert.Equal(t, StatePrimary, c.GetState())
+}
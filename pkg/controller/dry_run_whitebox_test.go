@@ -0,0 +1,77 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"log/slog"
+	"net/netip"
+	"os"
+	"testing"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/bgpserver"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/command"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/nftables"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/systemd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRun_SideEffectsAreRecorded(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{}))
+	fakeSystemdConnector := systemd.NewFakeSystemdConnector().(*systemd.FakeSystemdConnector)
+	fakeNftablesConnector := nftables.NewFakeNftablesConnector().(*nftables.FakeNftablesConnector)
+	fakeBgpServerConnector := bgpserver.NewFakeBgpServerConnector().(*bgpserver.FakeBgpServerConnector)
+	c := NewController(
+		logger,
+		WithGlobalInterfaceName("dummy-global-interface-name"),
+		WithHostAddress("10.0.0.1"),
+		WithDBServingPort(3306),
+		WithDBAclChainName("dummy-chain-name"),
+		WithSystemdConnector(fakeSystemdConnector),
+		WithMariaDBConnector(mariadb.NewFakeMariaDBConnector()),
+		WithNftablesConnector(fakeNftablesConnector),
+		WithBgpServerConnector(fakeBgpServerConnector),
+		WithDryRun(command.NewRecorder(logger)),
+	)
+	assert.True(t, c.DryRun())
+
+	c.setState(StateCandidate)
+	assert.NoError(t, c.triggerRunOnStateChangesToCandidate())
+
+	// nothing has been changed.
+	assert.Empty(t, fakeSystemdConnector.ServiceStarted)
+	assert.Empty(t, fakeNftablesConnector.Rules["dummy-chain-name"])
+	_, flushed := fakeNftablesConnector.Timestamp["FlushChain"]
+	assert.False(t, flushed)
+
+	// but the state is advertised.
+	route := fakeBgpServerConnector.AdvertisedRoutes[netip.MustParsePrefix("10.0.0.1/32")]
+	assert.Equal(t, bgpCommunityCandidate, route.Community)
+
+	recorded := make(map[string]string)
+	for _, cmd := range c.DryRunCommands(0) {
+		recorded[cmd.Command] = cmd.Component
+	}
+	assert.Equal(t, "systemd", recorded["systemctl start mariadb"])
+	assert.Equal(t, "mariadb", recorded["mysql -e set global read_only=1"])
+	assert.Equal(t, "nftables", recorded["nft flush chain filter dummy-chain-name"])
+	assert.Len(t, c.DryRunCommands(1), 1)
+}
+
+func TestDryRun_Disabled(t *testing.T) {
+	c := _newFakeController()
+	assert.False(t, c.DryRun())
+	assert.Empty(t, c.DryRunCommands(0))
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/bgpserver"
+)
+
+const (
+	// bgpLargeCommunityGTIDGlobalAdmin is the global administrator of the large community that carries the gtid sequence.
+	// the local data are the upper and the lower 32 bits of the sequence (for example 65200:0:100 means 100 transactions).
+	bgpLargeCommunityGTIDGlobalAdmin = 65200
+)
+
+// gtidLargeCommunity returns the large community that advertises the given gtid sequence.
+func gtidLargeCommunity(seq uint64) bgpserver.LargeCommunity {
+	return bgpserver.LargeCommunity{
+		GlobalAdmin: bgpLargeCommunityGTIDGlobalAdmin,
+		LocalData1:  uint32(seq >> 32),
+		LocalData2:  uint32(seq),
+	}
+}
+
+// parseGTIDLargeCommunity returns the gtid sequence if the large community advertises that.
+func parseGTIDLargeCommunity(comm bgpserver.LargeCommunity) (uint64, bool) {
+	if comm.GlobalAdmin != bgpLargeCommunityGTIDGlobalAdmin {
+		return 0, false
+	}
+
+	return uint64(comm.LocalData1)<<32 | uint64(comm.LocalData2), true
+}
+
+// observeGTIDSequence observes the transactions applied to the local MariaDB.
+// the sequence is observed only in the states that MariaDB is running as a replica.
+// the sequence of multiple replication domains isn't comparable between the nodes,
+// so it is left unknown and the election falls back to the priority and the address.
+func (c *Controller) observeGTIDSequence() {
+	c.gtidSequenceKnown = false
+	if state := c.GetState(); state != StateReplica && state != StateCandidate {
+		return
+	}
+	if c.currentMariaDBHealth == dbHealthCheckResultNG {
+		return
+	}
+
+	pos, err := c.mariaDBConnector.ShowGTIDCurrentPos()
+	if err != nil {
+		c.logger.Warn("failed to observe gtid_current_pos", "error", err)
+		return
+	}
+	if len(pos) > 1 {
+		c.logger.Debug("gtid_current_pos has multiple domains. the gtid sequence isn't compared.", "gtid", pos.String())
+		return
+	}
+	c.gtidSequence = pos.Transactions()
+	c.gtidSequenceKnown = true
+}
+
+// refreshGTIDAdvertisement re-advertises the route when the gtid sequence has progressed.
+// the function never returns an error because that is called from the keep handlers.
+func (c *Controller) refreshGTIDAdvertisement() {
+	if !c.gtidSequenceKnown || c.gtidSequence == c.advertisedGTIDSequence {
+		return
+	}
+
+	if err := c.advertiseSelfNetIFAddress(); err != nil {
+		c.logger.Warn("failed to advertise the gtid sequence", "error", err, "sequence", c.gtidSequence)
+	}
+}
+
+// moreAdvancedNeighborExists returns true if a replica or candidate neighbor has applied more transactions.
+// the neighbor that doesn't advertise the sequence isn't regarded as advanced.
+// if the own sequence is unknown, any neighbor that advertises the sequence may be advanced.
+func (c *Controller) moreAdvancedNeighborExists() bool {
+	for _, state := range []State{StateReplica, StateCandidate} {
+		for _, n := range c.currentNeighbors[state] {
+			if seq, ok := c.neighborGTIDSequences[n]; ok && (!c.gtidSequenceKnown || seq > c.gtidSequence) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// outranks returns true if this controller precedes the neighbor in the election.
+// the controller that has applied more transactions wins to minimize the data loss,
+// then the higher priority wins, and the lower address breaks the tie,
+// so every controller that observes the same candidates reaches the same result.
+// the controller that doesn't know its own sequence never outranks the neighbor that advertises one.
+func (c *Controller) outranks(n neighbor) bool {
+	if neighborSeq, ok := c.neighborGTIDSequences[n]; ok {
+		if !c.gtidSequenceKnown {
+			return false
+		}
+		if c.gtidSequence != neighborSeq {
+			return c.gtidSequence > neighborSeq
+		}
+	}
+	if neighborPriority := c.neighborPriorities[n]; c.priority != neighborPriority {
+		return c.priority > neighborPriority
+	}
+
+	return compareAddress(c.hostAddress, string(n)) < 0
+}
+
+// winsElection returns true if this controller outranks all the candidate neighbors.
+func (c *Controller) winsElection() bool {
+	for _, n := range c.currentNeighbors[StateCandidate] {
+		if !c.outranks(n) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compareAddress compares the addresses numerically.
+// the addresses that can't be parsed are compared as strings.
+func compareAddress(a string, b string) int {
+	addrA, errA := netip.ParseAddr(a)
+	addrB, errB := netip.ParseAddr(b)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+
+	return addrA.Compare(addrB)
+}
@@ -0,0 +1,243 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/bgpserver"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareAddress(t *testing.T) {
+	// numerically, not lexicographically.
+	assert.Negative(t, compareAddress("10.0.0.9", "10.0.0.10"))
+	assert.Positive(t, compareAddress("10.0.0.10", "10.0.0.9"))
+	assert.Zero(t, compareAddress("10.0.0.1", "10.0.0.1"))
+}
+
+func TestOutranks(t *testing.T) {
+	c := _newFakeController()
+
+	// the same priority is broken by the address.
+	assert.True(t, c.outranks("10.0.0.2"))
+
+	// the higher priority wins.
+	c.neighborPriorities["10.0.0.2"] = 100
+	assert.False(t, c.outranks("10.0.0.2"))
+	WithPriority(200)(c)
+	assert.True(t, c.outranks("10.0.0.2"))
+}
+
+func TestDecideNextStateOnCandidate_WinsElection(t *testing.T) {
+	c := _newFakeController()
+	c.currentNeighbors[StateCandidate] = []neighbor{"10.0.0.2", "10.0.0.3"}
+	c.currentMariaDBHealth = dbHealthCheckResultOK
+	c.readyToPrimary = readytoPrimaryJudgeOK
+
+	// the winner waits for the others to fall back.
+	assert.Equal(t, StateCandidate, c.decideNextStateOnCandidate())
+
+	c.currentNeighbors[StateCandidate] = []neighbor{}
+	c.currentNeighbors[StateFault] = []neighbor{"10.0.0.2", "10.0.0.3"}
+	assert.Equal(t, StatePrimary, c.decideNextStateOnCandidate())
+}
+
+func TestDecideNextStateOnCandidate_LosesElection(t *testing.T) {
+	c := _newFakeController()
+	c.currentNeighbors[StateCandidate] = []neighbor{"10.0.0.2", "10.0.0.3"}
+	c.neighborPriorities["10.0.0.3"] = 100
+	c.currentMariaDBHealth = dbHealthCheckResultOK
+	c.readyToPrimary = readytoPrimaryJudgeOK
+
+	assert.Equal(t, StateFault, c.decideNextStateOnCandidate())
+}
+
+func TestDecideNextStateOnFault_MultiplePrimaries(t *testing.T) {
+	c := _newFakeController()
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2", "10.0.0.3"}
+
+	assert.Equal(t, StateFault, c.decideNextStateOnFault())
+}
+
+func TestPreDecideNextStateHandler_SortsNeighbors(t *testing.T) {
+	c := _newFakeController()
+	fakeBgpServerConnector := c.bgpServerConnector.(*bgpserver.FakeBgpServerConnector)
+	fakeBgpServerConnector.Routes = []bgpserver.Route{
+		{Prefix: netip.MustParsePrefix("10.0.0.10/32"), Community: bgpCommunityReplica},
+		{Prefix: netip.MustParsePrefix("10.0.0.9/32"), Community: bgpCommunityReplica},
+		{Prefix: netip.MustParsePrefix("10.0.0.3/32"), Community: bgpCommunityPrimary},
+	}
+
+	assert.NoError(t, c.preDecideNextStateHandler())
+	assert.Equal(t, []neighbor{"10.0.0.9", "10.0.0.10"}, c.currentNeighbors[StateReplica])
+}
+
+func TestTriggerRunOnStateKeepsReplica_FollowsNewPrimary(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateFault)
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+	assert.NoError(t, c.triggerRunOnStateChangesToReplica())
+
+	fakeMariaDBConnector := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	assert.Equal(t, "10.0.0.2", fakeMariaDBConnector.MasterConfig.Host)
+
+	// another node has been promoted while this controller stays replica.
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.3"}
+	assert.NoError(t, c.triggerRunOnStateKeepsReplica())
+	assert.Equal(t, "10.0.0.3", fakeMariaDBConnector.MasterConfig.Host)
+	assert.Equal(t, neighbor("10.0.0.3"), c.replicationSource)
+	assert.Equal(t, uint(0), c.replicationStatusCheckFailCount)
+}
+
+func TestTriggerRunOnStateKeepsReplica_FollowsNewPrimaryWhileReplicationBroken(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateFault)
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+	assert.NoError(t, c.triggerRunOnStateChangesToReplica())
+
+	// the primary has died, so the io thread can't connect to it.
+	fakeMariaDBConnector := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConnector.ReplicationStatusOverride = mariadb.ReplicationStatus{
+		mariadb.ReplicationStatusSlaveIORunning: "Connecting",
+	}
+	c.currentNeighbors[StatePrimary] = []neighbor{}
+	c.currentNeighbors[StateCandidate] = []neighbor{"10.0.0.3"}
+	for i := 0; i < replicationStatusCheckThreshold-1; i++ {
+		assert.NoError(t, c.triggerRunOnStateKeepsReplica())
+	}
+	assert.Equal(t, uint(replicationStatusCheckThreshold-1), c.replicationStatusCheckFailCount)
+
+	// the candidate is promoted. the replica follows it even though the status check still fails,
+	// and the failures of the replication from the dead primary are forgotten.
+	c.currentNeighbors[StateCandidate] = []neighbor{}
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.3"}
+	assert.NoError(t, c.triggerRunOnStateKeepsReplica())
+	assert.Equal(t, "10.0.0.3", fakeMariaDBConnector.MasterConfig.Host)
+	assert.Equal(t, neighbor("10.0.0.3"), c.replicationSource)
+	assert.Equal(t, uint(1), c.replicationStatusCheckFailCount)
+
+	fakeMariaDBConnector.ReplicationStatusOverride = nil
+	assert.NoError(t, c.triggerRunOnStateKeepsReplica())
+	assert.Equal(t, uint(0), c.replicationStatusCheckFailCount)
+}
+
+func TestGTIDLargeCommunity(t *testing.T) {
+	comm := gtidLargeCommunity(1<<32 + 100)
+	assert.Equal(t, "65200:1:100", comm.String())
+
+	seq, ok := parseGTIDLargeCommunity(comm)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1<<32+100), seq)
+
+	_, ok = parseGTIDLargeCommunity(bgpserver.LargeCommunity{GlobalAdmin: 65001})
+	assert.False(t, ok)
+}
+
+func TestPreDecideNextStateHandler_NeighborGTIDSequence(t *testing.T) {
+	c := _newFakeController()
+	fakeBgpServerConnector := c.bgpServerConnector.(*bgpserver.FakeBgpServerConnector)
+	fakeBgpServerConnector.Routes = []bgpserver.Route{
+		{Prefix: netip.MustParsePrefix("10.0.0.2/32"), Community: bgpCommunityReplica},
+		{Prefix: netip.MustParsePrefix("10.0.0.2/32"), LargeCommunities: []bgpserver.LargeCommunity{gtidLargeCommunity(120)}},
+	}
+
+	assert.NoError(t, c.preDecideNextStateHandler())
+	assert.Equal(t, []neighbor{"10.0.0.2"}, c.currentNeighbors[StateReplica])
+	assert.Equal(t, uint64(120), c.neighborGTIDSequences["10.0.0.2"])
+}
+
+func TestOutranks_GTIDSequence(t *testing.T) {
+	c := _newFakeController()
+	WithPriority(200)(c)
+	c.gtidSequence = 100
+	c.gtidSequenceKnown = true
+
+	// the more advanced neighbor wins regardless of the priority.
+	c.neighborGTIDSequences["10.0.0.2"] = 120
+	assert.False(t, c.outranks("10.0.0.2"))
+
+	c.neighborGTIDSequences["10.0.0.2"] = 80
+	assert.True(t, c.outranks("10.0.0.2"))
+}
+
+func TestOutranks_UnknownGTIDSequence(t *testing.T) {
+	c := _newFakeController()
+	WithPriority(200)(c)
+	// the stale sequence must not be used.
+	c.gtidSequence = 100
+	c.gtidSequenceKnown = false
+
+	c.neighborGTIDSequences["10.0.0.2"] = 80
+	assert.False(t, c.outranks("10.0.0.2"))
+
+	// the neighbor that doesn't advertise the sequence is compared by the priority.
+	delete(c.neighborGTIDSequences, "10.0.0.2")
+	assert.True(t, c.outranks("10.0.0.2"))
+}
+
+func TestDecideNextStateOnCandidate_DefersToMoreAdvancedReplica(t *testing.T) {
+	c := _newFakeController()
+	c.currentNeighbors[StateReplica] = []neighbor{"10.0.0.2"}
+	c.currentMariaDBHealth = dbHealthCheckResultOK
+	c.readyToPrimary = readytoPrimaryJudgeOK
+	c.gtidSequence = 100
+	c.gtidSequenceKnown = true
+
+	c.neighborGTIDSequences["10.0.0.2"] = 120
+	assert.Equal(t, StateFault, c.decideNextStateOnCandidate())
+
+	c.neighborGTIDSequences["10.0.0.2"] = 100
+	assert.Equal(t, StatePrimary, c.decideNextStateOnCandidate())
+
+	// the candidate can't tell whether the replica is more advanced.
+	c.gtidSequenceKnown = false
+	assert.Equal(t, StateFault, c.decideNextStateOnCandidate())
+}
+
+func TestObserveGTIDSequence_MultipleDomains(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateReplica)
+	c.currentMariaDBHealth = dbHealthCheckResultOK
+	fakeMariaDBConnector := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConnector.GTIDCurrentPos, _ = mariadb.ParseGTIDSet("0-1-100,1-1-20")
+
+	c.observeGTIDSequence()
+	assert.False(t, c.gtidSequenceKnown)
+
+	fakeMariaDBConnector.GTIDCurrentPos, _ = mariadb.ParseGTIDSet("0-1-100")
+	c.observeGTIDSequence()
+	assert.True(t, c.gtidSequenceKnown)
+	assert.Equal(t, uint64(100), c.gtidSequence)
+}
+
+func TestRefreshGTIDAdvertisement(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateReplica)
+	c.currentMariaDBHealth = dbHealthCheckResultOK
+	fakeMariaDBConnector := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConnector.GTIDCurrentPos = mariadb.GTIDSet{0: {DomainID: 0, ServerID: 1, SeqNo: 100}}
+
+	c.observeGTIDSequence()
+	c.refreshGTIDAdvertisement()
+
+	fakeBgpServerConnector := c.bgpServerConnector.(*bgpserver.FakeBgpServerConnector)
+	route := fakeBgpServerConnector.AdvertisedRoutes[netip.MustParsePrefix("10.0.0.1/32")]
+	assert.Equal(t, bgpCommunityReplica, route.Community)
+	assert.Equal(t, []bgpserver.LargeCommunity{gtidLargeCommunity(100)}, route.LargeCommunities)
+	assert.Equal(t, uint64(100), c.advertisedGTIDSequence)
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/bgpserver"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+)
+
+const (
+	// bgpLargeCommunityEpochGlobalAdmin is the global administrator of the large community that carries the cluster epoch.
+	// the local data are the upper and the lower 32 bits of the epoch (for example 65300:0:3 means the epoch 3).
+	bgpLargeCommunityEpochGlobalAdmin = 65300
+)
+
+// epochLargeCommunity returns the large community that advertises the given cluster epoch.
+func epochLargeCommunity(epoch uint64) bgpserver.LargeCommunity {
+	return bgpserver.LargeCommunity{
+		GlobalAdmin: bgpLargeCommunityEpochGlobalAdmin,
+		LocalData1:  uint32(epoch >> 32),
+		LocalData2:  uint32(epoch),
+	}
+}
+
+// parseEpochLargeCommunity returns the cluster epoch if the large community advertises that.
+func parseEpochLargeCommunity(comm bgpserver.LargeCommunity) (uint64, bool) {
+	if comm.GlobalAdmin != bgpLargeCommunityEpochGlobalAdmin {
+		return 0, false
+	}
+
+	return uint64(comm.LocalData1)<<32 | uint64(comm.LocalData2), true
+}
+
+// observeClusterEpoch raises the cluster epoch that this controller knows.
+// the cluster epoch never decreases.
+func (c *Controller) observeClusterEpoch(epoch uint64) {
+	if epoch <= c.clusterEpoch {
+		return
+	}
+
+	c.logger.Info("the cluster epoch is updated", "from", c.clusterEpoch, "to", epoch)
+	c.clusterEpoch = epoch
+	dbControllerClusterEpochGauge.Set(float64(epoch))
+}
+
+// rejectStalePrimaries removes the primary neighbors that advertise the older epoch than the cluster epoch.
+// such a primary has been replaced while it was partitioned, so this controller never follows or yields to that.
+// the primary that doesn't advertise the epoch is never regarded as stale.
+func (c *Controller) rejectStalePrimaries() {
+	for _, n := range c.currentNeighbors[StatePrimary] {
+		if epoch, ok := c.neighborEpochs[n]; ok {
+			c.observeClusterEpoch(epoch)
+		}
+	}
+
+	c.currentNeighbors[StatePrimary] = slices.DeleteFunc(c.currentNeighbors[StatePrimary], func(n neighbor) bool {
+		epoch, ok := c.neighborEpochs[n]
+		if !ok || epoch >= c.clusterEpoch {
+			return false
+		}
+
+		c.logger.Warn("ignore the stale primary", "neighbor", n, "epoch", epoch, "cluster epoch", c.clusterEpoch)
+		return true
+	})
+}
+
+// persistedEpoch returns the epoch of the latest heartbeat in the local MariaDB.
+// zero is returned if no heartbeat has been written.
+func (c *Controller) persistedEpoch() (uint64, error) {
+	hb, err := c.mariaDBConnector.ShowHeartbeat(managementDatabaseName, heartbeatTableName)
+	if errors.Is(err, mariadb.ErrHeartbeatNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return hb.Epoch, nil
+}
+
+// beginEpoch starts the new epoch on the promotion.
+// the epoch is greater than any epoch that this controller knows, including the one replicated to the local MariaDB.
+func (c *Controller) beginEpoch() error {
+	persisted, err := c.persistedEpoch()
+	if err != nil {
+		return err
+	}
+	c.observeClusterEpoch(persisted)
+	c.observeClusterEpoch(c.clusterEpoch + 1)
+	c.epoch = c.clusterEpoch
+
+	return nil
+}
+
+// resumeEpoch takes over the epoch of the running primary on startup.
+// if the local MariaDB has no epoch, the new epoch is started.
+func (c *Controller) resumeEpoch() error {
+	persisted, err := c.persistedEpoch()
+	if err != nil {
+		return err
+	}
+	if persisted == 0 {
+		return c.beginEpoch()
+	}
+	if persisted < c.clusterEpoch {
+		return fmt.Errorf("the epoch of the local MariaDB is stale (epoch=%d, cluster epoch=%d)", persisted, c.clusterEpoch)
+	}
+
+	c.observeClusterEpoch(persisted)
+	c.epoch = persisted
+	return nil
+}
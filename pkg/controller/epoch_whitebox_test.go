@@ -0,0 +1,148 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/bgpserver"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEpochLargeCommunity(t *testing.T) {
+	comm := epochLargeCommunity(1<<32 + 3)
+	assert.Equal(t, "65300:1:3", comm.String())
+
+	epoch, ok := parseEpochLargeCommunity(comm)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1<<32+3), epoch)
+
+	_, ok = parseEpochLargeCommunity(gtidLargeCommunity(3))
+	assert.False(t, ok)
+}
+
+func TestPreDecideNextStateHandler_RejectsStalePrimary(t *testing.T) {
+	c := _newFakeController()
+	fakeBgpServerConnector := c.bgpServerConnector.(*bgpserver.FakeBgpServerConnector)
+	fakeBgpServerConnector.Routes = []bgpserver.Route{
+		{Prefix: netip.MustParsePrefix("10.0.0.2/32"), Community: bgpCommunityPrimary},
+		{Prefix: netip.MustParsePrefix("10.0.0.2/32"), LargeCommunities: []bgpserver.LargeCommunity{epochLargeCommunity(5)}},
+		{Prefix: netip.MustParsePrefix("10.0.0.3/32"), Community: bgpCommunityPrimary},
+		{Prefix: netip.MustParsePrefix("10.0.0.3/32"), LargeCommunities: []bgpserver.LargeCommunity{epochLargeCommunity(4)}},
+	}
+
+	assert.NoError(t, c.preDecideNextStateHandler())
+	assert.Equal(t, uint64(5), c.clusterEpoch)
+	assert.Equal(t, []neighbor{"10.0.0.2"}, c.currentNeighbors[StatePrimary])
+
+	// the zombie primary is never followed even after the new primary disappears.
+	fakeBgpServerConnector.Routes = fakeBgpServerConnector.Routes[2:]
+	assert.NoError(t, c.preDecideNextStateHandler())
+	assert.False(t, c.currentNeighbors.primaryNodeExists())
+}
+
+func TestPreDecideNextStateHandler_PrimaryWithoutEpoch(t *testing.T) {
+	c := _newFakeController()
+	c.clusterEpoch = 5
+	fakeBgpServerConnector := c.bgpServerConnector.(*bgpserver.FakeBgpServerConnector)
+	fakeBgpServerConnector.Routes = []bgpserver.Route{
+		{Prefix: netip.MustParsePrefix("10.0.0.2/32"), Community: bgpCommunityPrimary},
+	}
+
+	assert.NoError(t, c.preDecideNextStateHandler())
+	assert.Equal(t, []neighbor{"10.0.0.2"}, c.currentNeighbors[StatePrimary])
+}
+
+func TestDecideNextStateOnPrimary_StalePrimary(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StatePrimary)
+	c.currentMariaDBHealth = dbHealthCheckResultOK
+	c.epoch = 5
+	c.clusterEpoch = 5
+
+	// the other primary is the zombie, so this controller keeps primary.
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+	c.neighborEpochs = map[neighbor]uint64{"10.0.0.2": 4}
+	c.rejectStalePrimaries()
+	assert.Equal(t, StatePrimary, c.decideNextStateOnPrimary())
+
+	// this controller is the zombie.
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+	c.neighborEpochs = map[neighbor]uint64{"10.0.0.2": 6}
+	c.rejectStalePrimaries()
+	assert.Equal(t, StateFault, c.decideNextStateOnPrimary())
+}
+
+func TestTriggerRunOnStateChangesToPrimary_BeginsEpoch(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateCandidate)
+	c.clusterEpoch = 3
+	fakeMariaDBConnector := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	// the heartbeat of the previous primary has been replicated.
+	fakeMariaDBConnector.Heartbeat = &mariadb.Heartbeat{ServerID: 2, Timestamp: time.Now(), Epoch: 7}
+
+	c.setState(StatePrimary)
+	assert.NoError(t, c.triggerRunOnStateChangesToPrimary())
+	assert.Equal(t, uint64(8), c.epoch)
+	assert.Equal(t, uint64(8), c.clusterEpoch)
+	assert.Equal(t, uint64(8), fakeMariaDBConnector.Heartbeat.Epoch)
+
+	fakeBgpServerConnector := c.bgpServerConnector.(*bgpserver.FakeBgpServerConnector)
+	route := fakeBgpServerConnector.AdvertisedRoutes[netip.MustParsePrefix("10.0.0.1/32")]
+	assert.Equal(t, []bgpserver.LargeCommunity{epochLargeCommunity(8)}, route.LargeCommunities)
+}
+
+func TestResumeEpoch(t *testing.T) {
+	c := _newFakeController()
+	fakeMariaDBConnector := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+
+	// nothing is persisted.
+	assert.NoError(t, c.resumeEpoch())
+	assert.Equal(t, uint64(1), c.epoch)
+
+	fakeMariaDBConnector.Heartbeat = &mariadb.Heartbeat{ServerID: 1, Timestamp: time.Now(), Epoch: 4}
+	assert.NoError(t, c.resumeEpoch())
+	assert.Equal(t, uint64(4), c.epoch)
+
+	// the cluster has promoted another node while this controller was down.
+	c.clusterEpoch = 6
+	assert.Error(t, c.resumeEpoch())
+}
+
+func TestBeginEpoch_MissingHeartbeatTable(t *testing.T) {
+	c := _newFakeController()
+	fakeMariaDBConnector := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	// the connector reports the missing table on the fresh or upgraded cluster as no heartbeat.
+	fakeMariaDBConnector.ShowHeartbeatErr = fmt.Errorf("%w: management.heartbeat doesn't exist", mariadb.ErrHeartbeatNotFound)
+
+	c.setState(StatePrimary)
+	assert.NoError(t, c.triggerRunOnStateChangesToPrimary())
+	assert.Equal(t, uint64(1), c.epoch)
+
+	c = _newFakeController()
+	c.mariaDBConnector.(*mariadb.FakeMariaDBConnector).ShowHeartbeatErr = fakeMariaDBConnector.ShowHeartbeatErr
+	assert.NoError(t, c.resumeEpoch())
+	assert.Equal(t, uint64(1), c.epoch)
+
+	// the other errors still fail the promotion, the epoch may be unknown.
+	c = _newFakeController()
+	c.mariaDBConnector.(*mariadb.FakeMariaDBConnector).ShowHeartbeatErr = errors.New("connection refused")
+	assert.Error(t, c.beginEpoch())
+}
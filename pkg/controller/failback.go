@@ -0,0 +1,176 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+)
+
+// FailbackWindow is the time range of a day that allows the automatic failback.
+// the range wraps around midnight if End is before Start (for example 22:00-02:00).
+type FailbackWindow struct {
+	// Start is the offset from midnight.
+	Start time.Duration
+	// End is the offset from midnight.
+	End time.Duration
+}
+
+// ParseFailbackWindows parses the comma-separated time ranges like "01:00-05:00,22:00-23:30".
+// the empty string is parsed into no window, that allows the failback at any time.
+func ParseFailbackWindows(s string) ([]FailbackWindow, error) {
+	windows := make([]FailbackWindow, 0)
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		startEnd := strings.Split(strings.TrimSpace(part), "-")
+		if len(startEnd) != 2 {
+			return nil, fmt.Errorf("invalid failback window: %s", part)
+		}
+		start, err := parseClock(startEnd[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid failback window %s: %w", part, err)
+		}
+		end, err := parseClock(startEnd[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid failback window %s: %w", part, err)
+		}
+		windows = append(windows, FailbackWindow{Start: start, End: end})
+	}
+
+	return windows, nil
+}
+
+// parseClock parses "HH:MM" into the offset from midnight.
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Contains returns true if the given time is in the window.
+func (w FailbackWindow) Contains(t time.Time) bool {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := t.Sub(midnight)
+
+	if w.Start <= w.End {
+		return w.Start <= offset && offset < w.End
+	}
+	return w.Start <= offset || offset < w.End
+}
+
+// inFailbackWindow returns true if the failback is allowed at the given time.
+func (c *Controller) inFailbackWindow(t time.Time) bool {
+	if len(c.failbackWindows) == 0 {
+		return true
+	}
+
+	for _, w := range c.failbackWindows {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// preferredReplica returns the replica neighbor that has the higher priority than this controller.
+// the function returns the empty neighbor if there is no such replica.
+func (c *Controller) preferredReplica() neighbor {
+	var preferred neighbor
+	for _, n := range c.currentNeighbors[StateReplica] {
+		if c.neighborPriorities[n] <= c.priority {
+			continue
+		}
+		if preferred == "" || c.neighborPriorities[n] > c.neighborPriorities[preferred] {
+			preferred = n
+		}
+	}
+
+	return preferred
+}
+
+// tryFailback hands over the primary role to the preferred replica through the switchover.
+// the failback runs only if the preferred replica has been a replica for the stabilization period,
+// has caught up this primary, and the current time is in the failback windows.
+// the function never returns an error because the failback is optional.
+func (c *Controller) tryFailback(now time.Time) {
+	if !c.failbackEnabled {
+		return
+	}
+
+	preferred := c.preferredReplica()
+	if preferred == "" {
+		c.failbackCandidate = ""
+		return
+	}
+	if preferred != c.failbackCandidate {
+		// start the stabilization period.
+		c.failbackCandidate = preferred
+		c.failbackCandidateSince = now
+		c.logger.Info("found the preferred replica. waiting for the stabilization period.", "replica", preferred, "period", c.failbackStabilizationPeriod)
+		return
+	}
+
+	if now.Sub(c.failbackCandidateSince) < c.failbackStabilizationPeriod {
+		return
+	}
+	if !c.inFailbackWindow(now) {
+		c.logger.Debug("the failback is postponed until the failback window", "replica", preferred)
+		return
+	}
+	if !c.replicaCaughtUp(preferred) {
+		c.logger.Info("the preferred replica hasn't caught up. the failback is postponed.", "replica", preferred)
+		return
+	}
+
+	c.logger.Info("start failback to the preferred replica", "replica", preferred)
+	if err := c.switchoverWithReason(journalReasonFailback, time.Now().Add(c.switchoverTimeout)); err != nil {
+		c.logger.Warn("failed to failback. retry after the stabilization period.", "replica", preferred, "error", err)
+		c.failbackCandidateSince = now
+		return
+	}
+	c.failbackCandidate = ""
+}
+
+// replicaCaughtUp returns true if the replica has applied all transactions of this primary.
+func (c *Controller) replicaCaughtUp(replica neighbor) bool {
+	target, err := c.mariaDBConnector.ShowGTIDBinlogPos()
+	if err != nil {
+		c.logger.Debug("failed to show gtid_binlog_pos", "error", err)
+		return false
+	}
+
+	remote := mariadb.RemoteInstance{
+		Host:     string(replica),
+		Port:     c.dbReplicaSourcePort,
+		User:     c.dbReplicaUserName,
+		Password: c.dbReplicaPassword,
+	}
+	pos, err := c.mariaDBConnector.ShowRemoteGTIDSlavePos(remote)
+	if err != nil {
+		c.logger.Debug("failed to show gtid_slave_pos of the replica", "replica", replica, "error", err)
+		return false
+	}
+
+	return pos.Contains(target)
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFailbackWindows(t *testing.T) {
+	windows, err := ParseFailbackWindows("01:00-05:00, 22:00-02:30")
+	assert.NoError(t, err)
+	assert.Equal(t, []FailbackWindow{
+		{Start: time.Hour, End: 5 * time.Hour},
+		{Start: 22 * time.Hour, End: 2*time.Hour + 30*time.Minute},
+	}, windows)
+
+	windows, err = ParseFailbackWindows("")
+	assert.NoError(t, err)
+	assert.Empty(t, windows)
+
+	_, err = ParseFailbackWindows("01:00")
+	assert.Error(t, err)
+	_, err = ParseFailbackWindows("25:00-26:00")
+	assert.Error(t, err)
+}
+
+func TestFailbackWindow_Contains(t *testing.T) {
+	at := func(hour, min int) time.Time {
+		return time.Date(2025, 1, 1, hour, min, 0, 0, time.Local)
+	}
+
+	w := FailbackWindow{Start: time.Hour, End: 5 * time.Hour}
+	assert.True(t, w.Contains(at(1, 0)))
+	assert.True(t, w.Contains(at(4, 59)))
+	assert.False(t, w.Contains(at(5, 0)))
+	assert.False(t, w.Contains(at(0, 59)))
+
+	overMidnight := FailbackWindow{Start: 22 * time.Hour, End: 2 * time.Hour}
+	assert.True(t, overMidnight.Contains(at(23, 0)))
+	assert.True(t, overMidnight.Contains(at(1, 0)))
+	assert.False(t, overMidnight.Contains(at(12, 0)))
+}
+
+func _newFailbackController() *Controller {
+	c := _newFakeController()
+	WithPriority(100)(c)
+	WithFailback(time.Minute, nil)(c)
+	c.setState(StatePrimary)
+	c.currentNeighbors[StateReplica] = []neighbor{"10.0.0.2"}
+	c.neighborPriorities["10.0.0.2"] = 200
+
+	fakeMariaDBConn := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConn.GTIDBinlogPos, _ = mariadb.ParseGTIDSet("0-1-100")
+	fakeMariaDBConn.RemoteGTIDSlavePos["10.0.0.2"], _ = mariadb.ParseGTIDSet("0-1-100")
+
+	return c
+}
+
+func TestTryFailback_AfterStabilizationPeriod(t *testing.T) {
+	c := _newFailbackController()
+	now := time.Now()
+
+	c.tryFailback(now)
+	assert.Equal(t, StatePrimary, c.GetState())
+
+	c.tryFailback(now.Add(30 * time.Second))
+	assert.Equal(t, StatePrimary, c.GetState())
+
+	c.tryFailback(now.Add(time.Minute))
+	assert.Equal(t, StateFault, c.GetState())
+}
+
+func TestTryFailback_OutOfWindow(t *testing.T) {
+	c := _newFailbackController()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.Local)
+	c.failbackWindows = []FailbackWindow{{Start: time.Hour, End: 5 * time.Hour}}
+
+	c.tryFailback(now)
+	c.tryFailback(now.Add(time.Hour))
+	assert.Equal(t, StatePrimary, c.GetState())
+}
+
+func TestTryFailback_ReplicaNotCaughtUp(t *testing.T) {
+	c := _newFailbackController()
+	fakeMariaDBConn := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConn.RemoteGTIDSlavePos["10.0.0.2"], _ = mariadb.ParseGTIDSet("0-1-99")
+	now := time.Now()
+
+	c.tryFailback(now)
+	c.tryFailback(now.Add(time.Hour))
+	assert.Equal(t, StatePrimary, c.GetState())
+	assert.False(t, fakeMariaDBConn.ReadOnlyVariable)
+}
+
+func TestTryFailback_LowerPriorityReplica(t *testing.T) {
+	c := _newFailbackController()
+	c.neighborPriorities["10.0.0.2"] = 50
+	now := time.Now()
+
+	c.tryFailback(now)
+	c.tryFailback(now.Add(time.Hour))
+	assert.Equal(t, StatePrimary, c.GetState())
+}
+
+func TestTryFailback_Disabled(t *testing.T) {
+	c := _newFailbackController()
+	c.failbackEnabled = false
+	now := time.Now()
+
+	c.tryFailback(now)
+	c.tryFailback(now.Add(time.Hour))
+	assert.Equal(t, StatePrimary, c.GetState())
+}
@@ -22,6 +22,14 @@ import (
 // decideNextStateOnFault determines the next state on fault state
 func (c *Controller) decideNextStateOnFault() State {
 	if c.currentNeighbors.primaryNodeExists() {
+		if len(c.currentNeighbors[StatePrimary]) > 1 {
+			c.logger.Warn("multiple primaries exist. keep fault state until they are resolved.", "primaries", c.currentNeighbors[StatePrimary])
+			return StateFault
+		}
+		if d := c.GetDivergence(); d != nil {
+			c.logger.Warn("the controller has diverged from the primary. keep fault state.", "errant gtid", d.ErrantGTID)
+			return StateFault
+		}
 		return StateReplica
 	}
 
@@ -30,6 +38,10 @@ func (c *Controller) decideNextStateOnFault() State {
 		return StateFault
 	}
 
+	if c.yieldsToHigherPriorityNeighbor(StateFault) {
+		return StateFault
+	}
+
 	// the fault controller is ready to transition to candidate state
 	// because network reachability is ok and no one candidate is here.
 	return StateCandidate
@@ -38,6 +50,14 @@ func (c *Controller) decideNextStateOnFault() State {
 // triggerRunOnStateChangesToFault transition to fault state in main loop.
 // In fault state, the controller just reflect the fault state to external resources.
 func (c *Controller) triggerRunOnStateChangesToFault() error {
+	// [STEP0]: draining the connections of the demoted primary
+	// that is done before advertising fault state, so no one is promoted while the transactions are committed.
+	if c.shouldDrainOnDemotion() {
+		if err := c.drainConnections(); err != nil {
+			c.logger.Warn("failed to drain the connections but ignored because i'm fault", "error", err)
+		}
+	}
+
 	// [STEP1]: configure bgp route
 	if err := c.advertiseSelfNetIFAddress(); err != nil {
 		c.logger.Warn("failed to advertise self-address in BGP but ignored because i'm fault", "error", err)
@@ -55,6 +75,8 @@ func (c *Controller) triggerRunOnStateChangesToFault() error {
 	if err := c.stopMariaDBService(); err != nil {
 		c.logger.Warn("failed to stop systemd mariadb process but ignored because i'm fault", "error", err)
 	}
+	// the master.info is removed when MariaDB is started again, so the empty replication status is expected.
+	c.replicationConfigured = false
 
 	c.logger.Info("fault state handler succeed")
 	return nil
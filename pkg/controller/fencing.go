@@ -0,0 +1,68 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+)
+
+// FencingPolicy specifies how the controller deals with the failure of fencing.
+type FencingPolicy string
+
+const (
+	// FencingPolicyRequired blocks the promotion if the fencing fails.
+	FencingPolicyRequired FencingPolicy = "required"
+	// FencingPolicyBestEffort promotes the controller even if the fencing fails.
+	FencingPolicyBestEffort FencingPolicy = "best-effort"
+)
+
+// fencePreviousPrimary isolates the last primary neighbor that this controller observed.
+// the function does nothing if the fencer isn't configured or no primary has been observed.
+func (c *Controller) fencePreviousPrimary() error {
+	if c.fencer == nil || c.lastPrimaryNeighbor == "" {
+		return nil
+	}
+
+	target := string(c.lastPrimaryNeighbor)
+	c.logger.Info("fencing the previous primary", "target", target)
+	if err := c.fencer.Fence(target); err != nil {
+		if c.fencingPolicy == FencingPolicyBestEffort {
+			c.logger.Warn("failed to fence the previous primary but ignored because of the fencing policy", "target", target, "error", err)
+			return nil
+		}
+		return fmt.Errorf("failed to fence the previous primary %s: %w", target, err)
+	}
+
+	c.logger.Info("fenced the previous primary", "target", target)
+	return nil
+}
+
+// forgetDemotedPrimary forgets the last primary neighbor if it still advertises another state.
+// such a node has stepped down by itself (e.g. switchover, failback or shutdown handoff)
+// and no longer accepts writes, so fencing it would only stop a healthy node.
+// a primary that has disappeared from the network is kept because it may be still alive.
+func (c *Controller) forgetDemotedPrimary() {
+	if c.lastPrimaryNeighbor == "" {
+		return
+	}
+
+	state, ok := c.currentNeighbors.stateOf(c.lastPrimaryNeighbor)
+	if !ok || state == StatePrimary {
+		return
+	}
+
+	c.logger.Info("the previous primary has demoted itself. it won't be fenced", "neighbor", c.lastPrimaryNeighbor, "state", state)
+	c.lastPrimaryNeighbor = ""
+}
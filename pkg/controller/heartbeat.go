@@ -0,0 +1,90 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+)
+
+// heartbeatTableName is the name of the heartbeat table on management DB.
+// the primary writes the current time to the table, and the replicas measure the replication lag with it.
+const heartbeatTableName = "heartbeat"
+
+// writeHeartbeat writes the heartbeat row of this controller to MariaDB.
+func (c *Controller) writeHeartbeat() error {
+	if err := c.mariaDBConnector.CreateHeartbeatTable(managementDatabaseName, heartbeatTableName); err != nil {
+		return err
+	}
+
+	return c.mariaDBConnector.WriteHeartbeat(managementDatabaseName, heartbeatTableName, time.Now(), c.epoch)
+}
+
+// observeHeartbeat observes the heartbeat replicated from the primary.
+// the epoch in the heartbeat raises the cluster epoch, and the end-to-end replication lag is measured with the timestamp.
+// unlike Seconds_Behind_Master, the lag includes the delay of the IO thread and is known while the replication is broken.
+// the lag is observed only while the primary is visible,
+// because the heartbeat stops when the primary is down and the lag grows regardless of the replication.
+func (c *Controller) observeHeartbeat() {
+	hb, err := c.mariaDBConnector.ShowHeartbeat(managementDatabaseName, heartbeatTableName)
+	if errors.Is(err, mariadb.ErrHeartbeatNotFound) {
+		// the primary hasn't written the heartbeat yet.
+		return
+	}
+	if err != nil {
+		c.logger.Warn("failed to show the heartbeat", "error", err)
+		return
+	}
+
+	c.observeClusterEpoch(hb.Epoch)
+	if !c.currentNeighbors.primaryNodeExists() {
+		return
+	}
+
+	lag := time.Since(hb.Timestamp)
+	if lag < 0 {
+		// the clock of the primary is ahead of this host.
+		lag = 0
+	}
+	c.setHeartbeatLag(lag, true)
+}
+
+// setHeartbeatLag updates the heartbeat lag and its metric.
+func (c *Controller) setHeartbeatLag(lag time.Duration, known bool) {
+	c.m.Lock()
+	c.heartbeatLag = lag
+	c.heartbeatLagKnown = known
+	c.m.Unlock()
+
+	if known {
+		dbControllerHeartbeatLagGauge.Set(lag.Seconds())
+	}
+}
+
+// resetHeartbeatLag forgets the heartbeat lag of the previous replication.
+func (c *Controller) resetHeartbeatLag() {
+	c.setHeartbeatLag(0, false)
+}
+
+// HeartbeatLag returns the replication lag measured with the heartbeat table in replica state.
+// the second return value is false if the lag hasn't been measured.
+func (c *Controller) HeartbeatLag() (time.Duration, bool) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	return c.heartbeatLag, c.heartbeatLagKnown
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteTestDataToMariaDB_WritesHeartbeat(t *testing.T) {
+	c := _newFakeController()
+	c.epoch = 42
+
+	assert.NoError(t, c.writeTestDataToMariaDB())
+	hb := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector).Heartbeat
+	assert.NotNil(t, hb)
+	assert.Equal(t, uint64(42), hb.Epoch)
+	assert.WithinDuration(t, time.Now(), hb.Timestamp, time.Second)
+}
+
+func TestObserveHeartbeat(t *testing.T) {
+	c := _newFakeController()
+	fakeMariaDBConn := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+
+	// no heartbeat has been written yet.
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+	c.observeHeartbeat()
+	_, known := c.HeartbeatLag()
+	assert.False(t, known)
+
+	fakeMariaDBConn.Heartbeat = &mariadb.Heartbeat{ServerID: 2, Timestamp: time.Now().Add(-30 * time.Second)}
+	c.observeHeartbeat()
+	lag, known := c.HeartbeatLag()
+	assert.True(t, known)
+	assert.InDelta(t, 30, lag.Seconds(), 1)
+
+	// the stopped heartbeat of the lost primary doesn't mean the replication lag.
+	c.currentNeighbors[StatePrimary] = nil
+	fakeMariaDBConn.Heartbeat.Timestamp = time.Now().Add(-time.Hour)
+	c.observeHeartbeat()
+	lag, _ = c.HeartbeatLag()
+	assert.InDelta(t, 30, lag.Seconds(), 1)
+
+	// the clock of the primary may be ahead.
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+	fakeMariaDBConn.Heartbeat.Timestamp = time.Now().Add(time.Minute)
+	c.observeHeartbeat()
+	lag, _ = c.HeartbeatLag()
+	assert.Equal(t, time.Duration(0), lag)
+}
+
+func TestReadyToBePromotedToPrimary_HeartbeatLag(t *testing.T) {
+	c, _ := _newPromotionController(mariadb.ReplicationStatus{
+		mariadb.ReplicationStatusReadMasterLogPos:    "200",
+		mariadb.ReplicationStatusGtidIOPos:           "0-2-100",
+		mariadb.ReplicationStatusSecondsBehindMaster: "0",
+	})
+	WithPromotionGate(time.Minute, time.Second, PromotionTimeoutPolicyStayCandidate)(c)
+	assert.Equal(t, readytoPrimaryJudgeOK, c.readyToBePromotedToPrimary())
+
+	// Seconds_Behind_Master doesn't count the transactions that the IO thread hasn't received.
+	c.setHeartbeatLag(2*time.Minute, true)
+	assert.Equal(t, readytoPrimaryJudgeNG, c.readyToBePromotedToPrimary())
+
+	c.resetHeartbeatLag()
+	assert.Equal(t, readytoPrimaryJudgeOK, c.readyToBePromotedToPrimary())
+}
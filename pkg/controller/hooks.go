@@ -0,0 +1,108 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"slices"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/hook"
+)
+
+// isDemotion returns true if the transition steps down from primary state.
+func isDemotion(from State, to State) bool {
+	return from == StatePrimary && to != StatePrimary
+}
+
+// runPreTransitionHooks runs the hooks before the handlers of the transition.
+// only the pre-promote hooks can abort the transition.
+// the demotion is never blocked because the controller steps down for the safety.
+func (c *Controller) runPreTransitionHooks(from State, to State) error {
+	if isDemotion(from, to) {
+		c.runHooksWithoutBlocking(hook.PointPreDemote, from, to)
+	}
+	if to == StatePrimary && from != StatePrimary {
+		return c.runHooks(hook.PointPrePromote, from, to)
+	}
+
+	return nil
+}
+
+// runPostTransitionHooks runs the hooks after the handlers of the transition succeeded.
+// the blocking hooks of the promotion and replica state fail the transition, so the controller goes to fault state.
+func (c *Controller) runPostTransitionHooks(from State, to State) error {
+	if isDemotion(from, to) {
+		c.runHooksWithoutBlocking(hook.PointPostDemote, from, to)
+	}
+
+	switch to {
+	case StatePrimary:
+		if from != StatePrimary {
+			return c.runHooks(hook.PointPostPromote, from, to)
+		}
+	case StateReplica:
+		return c.runHooks(hook.PointEnterReplica, from, to)
+	case StateFault:
+		c.runHooksWithoutBlocking(hook.PointEnterFault, from, to)
+	}
+
+	return nil
+}
+
+// runHooks runs the hooks at the point of the transition.
+func (c *Controller) runHooks(point hook.Point, from State, to State) error {
+	if !c.hookRunner.Has(point) {
+		return nil
+	}
+
+	return c.hookRunner.Run(c.hookEvent(point, from, to))
+}
+
+// runHooksWithoutBlocking runs the hooks at the point that can't be aborted.
+// the failure of the blocking hook is only logged.
+func (c *Controller) runHooksWithoutBlocking(point hook.Point, from State, to State) {
+	if err := c.runHooks(point, from, to); err != nil {
+		c.logger.Warn("the hook failed but the transition goes on", "point", point, "error", err)
+	}
+}
+
+// hookEvent describes the transition to the hooks.
+func (c *Controller) hookEvent(point hook.Point, from State, to State) hook.Event {
+	event := hook.Event{
+		Point:         point,
+		From:          string(from),
+		To:            string(to),
+		HostAddress:   c.hostAddress,
+		PeerAddresses: make([]string, 0),
+		Epoch:         c.clusterEpoch,
+	}
+
+	switch {
+	case to == StatePrimary:
+		event.PrimaryAddress = c.hostAddress
+	case c.currentNeighbors.primaryNodeExists():
+		event.PrimaryAddress = string(c.currentNeighbors[StatePrimary][0])
+	}
+	for _, neighbors := range c.currentNeighbors {
+		for _, n := range neighbors {
+			event.PeerAddresses = append(event.PeerAddresses, string(n))
+		}
+	}
+	slices.SortFunc(event.PeerAddresses, compareAddress)
+	if gtid, err := c.mariaDBConnector.ShowGTIDBinlogPos(); err == nil {
+		event.GTID = gtid.String()
+	}
+
+	return event
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/hook"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/stretchr/testify/assert"
+)
+
+func _newHookController(hooks ...hook.Hook) (*Controller, *hook.FakeExecutor) {
+	c := _newFakeController()
+	executor := hook.NewFakeExecutor().(*hook.FakeExecutor)
+	c.hookRunner = hook.NewRunner(c.logger, executor, hooks...)
+	return c, executor
+}
+
+func _executedPoints(executor *hook.FakeExecutor) []hook.Point {
+	points := make([]hook.Point, 0)
+	for _, h := range executor.Executed {
+		points = append(points, h.Point)
+	}
+	return points
+}
+
+func _allHooks(mode hook.Mode) []hook.Hook {
+	return []hook.Hook{
+		{Point: hook.PointPrePromote, Path: "pre-promote", Mode: mode},
+		{Point: hook.PointPostPromote, Path: "post-promote", Mode: mode},
+		{Point: hook.PointPreDemote, Path: "pre-demote", Mode: mode},
+		{Point: hook.PointPostDemote, Path: "post-demote", Mode: mode},
+		{Point: hook.PointEnterFault, Path: "enter-fault", Mode: mode},
+		{Point: hook.PointEnterReplica, Path: "enter-replica", Mode: mode},
+	}
+}
+
+func TestHooks_Promotion(t *testing.T) {
+	c, executor := _newHookController(_allHooks(hook.ModeBlocking)...)
+	c.currentNeighbors[StateReplica] = []neighbor{"10.0.0.3", "10.0.0.2"}
+	c.mariaDBConnector.(*mariadb.FakeMariaDBConnector).GTIDBinlogPos, _ = mariadb.ParseGTIDSet("0-1-100")
+	c.setState(StateCandidate)
+	c.setState(StatePrimary)
+
+	assert.NoError(t, c.triggerRunOnStateChanges())
+	assert.Equal(t, []hook.Point{hook.PointPrePromote, hook.PointPostPromote}, _executedPoints(executor))
+	assert.Contains(t, executor.Envs[0], "DBC_OLD_STATE=candidate")
+	assert.Contains(t, executor.Envs[0], "DBC_NEW_STATE=primary")
+	assert.Contains(t, executor.Envs[0], "DBC_PRIMARY_ADDRESS=10.0.0.1")
+	assert.Contains(t, executor.Envs[0], "DBC_PEER_ADDRESSES=10.0.0.2,10.0.0.3")
+	assert.Contains(t, executor.Envs[0], "DBC_GTID=0-1-100")
+}
+
+func TestHooks_BlockingPrePromoteFails(t *testing.T) {
+	c, executor := _newHookController(_allHooks(hook.ModeBlocking)...)
+	executor.Errs["pre-promote"] = errors.New("failed")
+	c.setState(StateCandidate)
+	c.setState(StatePrimary)
+
+	assert.Error(t, c.triggerRunOnStateChanges())
+	assert.Equal(t, []hook.Point{hook.PointPrePromote}, _executedPoints(executor))
+	// the promotion is aborted before MariaDB is touched.
+	_, ok := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector).Timestamp["StopReplica"]
+	assert.False(t, ok)
+}
+
+func TestHooks_DemotionIsNeverBlocked(t *testing.T) {
+	c, executor := _newHookController(_allHooks(hook.ModeBlocking)...)
+	executor.Errs["pre-demote"] = errors.New("failed")
+	executor.Errs["enter-fault"] = errors.New("failed")
+	c.setState(StatePrimary)
+	c.setState(StateFault)
+
+	assert.NoError(t, c.triggerRunOnStateChanges())
+	assert.Equal(t, []hook.Point{hook.PointPreDemote, hook.PointPostDemote, hook.PointEnterFault}, _executedPoints(executor))
+}
+
+func TestHooks_EnterReplica(t *testing.T) {
+	c, executor := _newHookController(_allHooks(hook.ModeAdvisory)...)
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+	c.setState(StateFault)
+	c.setState(StateReplica)
+
+	assert.NoError(t, c.triggerRunOnStateChanges())
+	assert.Equal(t, []hook.Point{hook.PointEnterReplica}, _executedPoints(executor))
+	assert.Contains(t, executor.Envs[0], "DBC_PRIMARY_ADDRESS=10.0.0.2")
+}
+
+func TestHooks_Switchover(t *testing.T) {
+	c, executor := _newHookController(_allHooks(hook.ModeAdvisory)...)
+	c.setState(StatePrimary)
+	c.currentNeighbors[StateReplica] = []neighbor{"10.0.0.2"}
+	fakeMariaDBConn := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConn.GTIDBinlogPos, _ = mariadb.ParseGTIDSet("0-1-100")
+	fakeMariaDBConn.RemoteGTIDSlavePos["10.0.0.2"], _ = mariadb.ParseGTIDSet("0-1-100")
+
+	assert.NoError(t, c.switchover())
+	assert.Equal(t, []hook.Point{hook.PointPreDemote, hook.PointPostDemote}, _executedPoints(executor))
+}
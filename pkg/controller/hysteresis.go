@@ -0,0 +1,136 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Transition is an edge of the state machine.
+type Transition struct {
+	From State
+	To   State
+}
+
+// String returns the transition in the form of "from:to".
+func (t Transition) String() string {
+	return fmt.Sprintf("%s:%s", t.From, t.To)
+}
+
+// ParseTransitionConfirmations parses the comma-separated confirmations like "primary:fault=3,replica:candidate=2".
+// each entry requires the transition to be observed in the given number of consecutive loops.
+// the empty string is parsed into no entry, that makes every transition immediate.
+func ParseTransitionConfirmations(s string) (map[Transition]uint, error) {
+	confirmations := make(map[Transition]uint)
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		edgeCount := strings.Split(strings.TrimSpace(part), "=")
+		if len(edgeCount) != 2 {
+			return nil, fmt.Errorf("invalid transition confirmation: %s", part)
+		}
+		fromTo := strings.Split(edgeCount[0], ":")
+		if len(fromTo) != 2 || fromTo[0] == "" || fromTo[1] == "" {
+			return nil, fmt.Errorf("invalid transition confirmation: %s", part)
+		}
+		count, err := strconv.ParseUint(edgeCount[1], 10, 32)
+		if err != nil || count == 0 {
+			return nil, fmt.Errorf("invalid transition confirmation %s: the count must be positive", part)
+		}
+		confirmations[Transition{From: State(fromTo[0]), To: State(fromTo[1])}] = uint(count)
+	}
+
+	return confirmations, nil
+}
+
+// requiredConfirmations returns the number of the consecutive loops that confirm the transition.
+func (c *Controller) requiredConfirmations(t Transition) uint {
+	if n, ok := c.transitionConfirmations[t]; ok {
+		return n
+	}
+	return 1
+}
+
+// isImmediateTransition returns true if the transition must not wait for the confirmations.
+// the conflicting writers must be stopped as soon as they are observed,
+// and the health checks of MariaDB have their own failure thresholds.
+func (c *Controller) isImmediateTransition(t Transition) bool {
+	if t.To != StateFault {
+		return false
+	}
+	if c.currentMariaDBHealth == dbHealthCheckResultNG {
+		return true
+	}
+
+	switch t.From {
+	case StatePrimary:
+		// dual-primary.
+		return c.currentNeighbors.primaryNodeExists()
+	case StateCandidate:
+		return c.currentNeighbors.primaryNodeExists() || c.currentNeighbors.candidateNodeExists()
+	}
+	return false
+}
+
+// confirmTransition dampens the decided transition until it is observed in the consecutive loops.
+// the function returns the next state if the transition is confirmed, otherwise the current state.
+// count is false if the loop is triggered by the bgp event,
+// because a flap fires several events in a moment.
+func (c *Controller) confirmTransition(nextState State, count bool) State {
+	t := Transition{From: c.GetState(), To: nextState}
+	if t.From == t.To || c.isImmediateTransition(t) {
+		c.resetPendingTransition()
+		return nextState
+	}
+
+	required := c.requiredConfirmations(t)
+	if required <= 1 {
+		c.resetPendingTransition()
+		return nextState
+	}
+
+	if c.pendingTransition != t {
+		c.resetPendingTransition()
+		c.pendingTransition = t
+	}
+	if count {
+		c.pendingTransitionCount++
+	}
+	dbControllerPendingTransitionGaugeVec.WithLabelValues(string(t.From), string(t.To)).Set(float64(c.pendingTransitionCount))
+
+	if c.pendingTransitionCount < required {
+		c.logger.Info("waiting for the confirmations of the transition", "transition", t.String(), "confirmed", c.pendingTransitionCount, "required", required)
+		return t.From
+	}
+	return nextState
+}
+
+// resetPendingTransition discards the unconfirmed transition.
+func (c *Controller) resetPendingTransition() {
+	if c.pendingTransition == (Transition{}) {
+		return
+	}
+
+	if c.pendingTransition.From == c.GetState() {
+		c.logger.Info("the pending transition is cancelled", "transition", c.pendingTransition.String(), "confirmed", c.pendingTransitionCount)
+	}
+	dbControllerPendingTransitionGaugeVec.WithLabelValues(string(c.pendingTransition.From), string(c.pendingTransition.To)).Set(0)
+	c.pendingTransition = Transition{}
+	c.pendingTransitionCount = 0
+}
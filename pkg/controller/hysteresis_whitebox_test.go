@@ -0,0 +1,99 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTransitionConfirmations(t *testing.T) {
+	confirmations, err := ParseTransitionConfirmations("primary:fault=3, replica:candidate=2")
+	assert.NoError(t, err)
+	assert.Equal(t, map[Transition]uint{
+		{From: StatePrimary, To: StateFault}:     3,
+		{From: StateReplica, To: StateCandidate}: 2,
+	}, confirmations)
+
+	confirmations, err = ParseTransitionConfirmations("")
+	assert.NoError(t, err)
+	assert.Empty(t, confirmations)
+
+	for _, invalid := range []string{"primary=3", "primary:fault", "primary:fault=0", ":fault=1", "primary:fault=x"} {
+		_, err := ParseTransitionConfirmations(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestConfirmTransition_Dampened(t *testing.T) {
+	c := _newFakeController()
+	WithTransitionConfirmations(map[Transition]uint{{From: StatePrimary, To: StateFault}: 3})(c)
+	c.setState(StatePrimary)
+	c.currentMariaDBHealth = dbHealthCheckResultOK
+
+	assert.Equal(t, StatePrimary, c.confirmTransition(StateFault, true))
+	assert.Equal(t, StatePrimary, c.confirmTransition(StateFault, true))
+	// the loop triggered by the bgp event isn't counted.
+	assert.Equal(t, StatePrimary, c.confirmTransition(StateFault, false))
+	assert.Equal(t, StateFault, c.confirmTransition(StateFault, true))
+}
+
+func TestConfirmTransition_Recovered(t *testing.T) {
+	c := _newFakeController()
+	WithTransitionConfirmations(map[Transition]uint{{From: StatePrimary, To: StateFault}: 2})(c)
+	c.setState(StatePrimary)
+	c.currentMariaDBHealth = dbHealthCheckResultOK
+
+	assert.Equal(t, StatePrimary, c.confirmTransition(StateFault, true))
+	// the route came back.
+	assert.Equal(t, StatePrimary, c.confirmTransition(StatePrimary, true))
+	assert.Equal(t, uint(0), c.pendingTransitionCount)
+	assert.Equal(t, StatePrimary, c.confirmTransition(StateFault, true))
+}
+
+func TestConfirmTransition_Immediate(t *testing.T) {
+	c := _newFakeController()
+	WithTransitionConfirmations(map[Transition]uint{{From: StatePrimary, To: StateFault}: 3})(c)
+	c.setState(StatePrimary)
+	c.currentMariaDBHealth = dbHealthCheckResultOK
+
+	// dual-primary
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+	assert.Equal(t, StateFault, c.confirmTransition(StateFault, true))
+
+	// unhealthy MariaDB
+	c.currentNeighbors = newNeighborSet()
+	c.currentMariaDBHealth = dbHealthCheckResultNG
+	assert.Equal(t, StateFault, c.confirmTransition(StateFault, true))
+}
+
+func TestRunControlLoop_DampensNetworkPartition(t *testing.T) {
+	c := _newFakeController()
+	WithTransitionConfirmations(map[Transition]uint{{From: StatePrimary, To: StateFault}: 2})(c)
+	c.setState(StatePrimary)
+
+	// no route is visible from the fake bgp server.
+	c.runControlLoop(context.Background(), false)
+	assert.Equal(t, StatePrimary, c.GetState())
+	assert.Equal(t, Transition{From: StatePrimary, To: StateFault}, c.pendingTransition)
+
+	c.runControlLoop(context.Background(), false)
+	assert.Equal(t, StateFault, c.GetState())
+	// the counter is reset by the transition.
+	assert.Equal(t, Transition{}, c.pendingTransition)
+	assert.Equal(t, uint(0), c.pendingTransitionCount)
+}
@@ -0,0 +1,161 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+)
+
+const (
+	// journalRotateSize is the size of the journal file that triggers the rotation.
+	// the rotated journal is kept as "<journal>.1" until the next rotation.
+	journalRotateSize = 1 << 20
+
+	journalReasonDecided     = "decided by the state machine"
+	journalReasonForced      = "forced to fault"
+	journalReasonMaintenance = "maintenance requested"
+	journalReasonSwitchover  = "switchover requested"
+	journalReasonFailback    = "failback to the preferred node"
+	journalReasonAdopted     = "adopted the running MariaDB on startup"
+	journalReasonShutdown    = "handed over on shutdown"
+	journalReasonDetached    = "detached on shutdown"
+)
+
+// JournalEntry is a record of the state transition of the controller.
+type JournalEntry struct {
+	Timestamp time.Time          `json:"timestamp"`
+	From      State              `json:"from"`
+	To        State              `json:"to"`
+	Neighbors map[State][]string `json:"neighbors"`
+	// GTID is the gtid_binlog_pos of MariaDB at the transition.
+	// it is empty if MariaDB didn't respond.
+	GTID   string `json:"gtid"`
+	Reason string `json:"reason"`
+}
+
+// recordJournal appends the state transition to the journal file.
+func (c *Controller) recordJournal(from State, to State, reason string) error {
+	if c.journalFilePath == "" {
+		return nil
+	}
+
+	entry := JournalEntry{
+		Timestamp: time.Now(),
+		From:      from,
+		To:        to,
+		Neighbors: make(map[State][]string),
+		Reason:    reason,
+	}
+	for state, neighbors := range c.currentNeighbors {
+		for _, n := range neighbors {
+			entry.Neighbors[state] = append(entry.Neighbors[state], string(n))
+		}
+	}
+	if gtid, err := c.mariaDBConnector.ShowGTIDBinlogPos(); err == nil {
+		entry.GTID = gtid.String()
+	}
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := c.rotateJournal(); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(c.journalFilePath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	// the journal must survive the crash of the host.
+	return f.Sync()
+}
+
+// rotateJournal moves the journal file aside when it grows over journalRotateSize.
+func (c *Controller) rotateJournal() error {
+	info, err := os.Stat(c.journalFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Size() < journalRotateSize {
+		return nil
+	}
+
+	return os.Rename(c.journalFilePath, c.journalFilePath+".1")
+}
+
+// JournalEntries returns the recorded state transitions in chronological order.
+// the function returns the last `limit` entries if limit is positive.
+func (c *Controller) JournalEntries(limit int) ([]JournalEntry, error) {
+	entries := make([]JournalEntry, 0)
+	if c.journalFilePath == "" {
+		return entries, nil
+	}
+
+	f, err := os.Open(c.journalFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// the last line may be torn by the crash while writing.
+			c.logger.Warn("skip the broken journal entry", "error", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if 0 < limit && limit < len(entries) {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries, nil
+}
+
+// lastJournalEntry returns the most recent state transition.
+// the function returns nil if nothing is recorded.
+func (c *Controller) lastJournalEntry() (*JournalEntry, error) {
+	entries, err := c.JournalEntries(1)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	return &entries[0], nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournal_RecordsTransitions(t *testing.T) {
+	c := _newFakeController()
+	c.journalFilePath = filepath.Join(t.TempDir(), "journal")
+	c.currentNeighbors[StateReplica] = []neighbor{"10.0.0.2"}
+	gtid, err := mariadb.ParseGTIDSet("0-1-100")
+	assert.NoError(t, err)
+	c.mariaDBConnector.(*mariadb.FakeMariaDBConnector).GTIDBinlogPos = gtid
+
+	c.setState(StateFault)
+	c.setState(StateFault)
+	c.forceTransitionToFault()
+	c.setState(StateCandidate)
+
+	entries, err := c.JournalEntries(0)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	assert.Equal(t, StateInitial, entries[0].From)
+	assert.Equal(t, StateFault, entries[0].To)
+	assert.Equal(t, journalReasonDecided, entries[0].Reason)
+	assert.Equal(t, "0-1-100", entries[0].GTID)
+	assert.Equal(t, []string{"10.0.0.2"}, entries[0].Neighbors[StateReplica])
+	assert.Equal(t, StateCandidate, entries[1].To)
+
+	last, err := c.lastJournalEntry()
+	assert.NoError(t, err)
+	assert.Equal(t, StateCandidate, last.To)
+}
+
+func TestJournal_Limit(t *testing.T) {
+	c := _newFakeController()
+	c.journalFilePath = filepath.Join(t.TempDir(), "journal")
+
+	c.setState(StateFault)
+	c.setState(StateCandidate)
+	c.setState(StatePrimary)
+
+	entries, err := c.JournalEntries(2)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, StateCandidate, entries[0].To)
+	assert.Equal(t, StatePrimary, entries[1].To)
+}
+
+func TestJournal_SkipsBrokenEntry(t *testing.T) {
+	c := _newFakeController()
+	c.journalFilePath = filepath.Join(t.TempDir(), "journal")
+
+	c.setState(StateFault)
+	f, err := os.OpenFile(c.journalFilePath, os.O_WRONLY|os.O_APPEND, 0o644)
+	assert.NoError(t, err)
+	_, err = f.WriteString(`{"timestamp":"20`)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	last, err := c.lastJournalEntry()
+	assert.NoError(t, err)
+	assert.Equal(t, StateFault, last.To)
+}
+
+func TestJournal_Empty(t *testing.T) {
+	c := _newFakeController()
+	c.journalFilePath = filepath.Join(t.TempDir(), "journal")
+
+	last, err := c.lastJournalEntry()
+	assert.NoError(t, err)
+	assert.Nil(t, last)
+}
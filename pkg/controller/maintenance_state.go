@@ -0,0 +1,114 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrMaintenanceNotAllowed is returned when the maintenance is requested in the state that serves the database.
+	ErrMaintenanceNotAllowed = errors.New("maintenance is only allowed in fault or replica state")
+	// ErrNotInMaintenance is returned when leaving the maintenance is requested out of maintenance state.
+	ErrNotInMaintenance = errors.New("the controller is not in maintenance state")
+)
+
+// maintenanceRequest is a request of entering/leaving the maintenance from the http-api goroutine.
+type maintenanceRequest struct {
+	// enter is true when entering the maintenance, false when leaving it.
+	enter bool
+	// result receives the result of the request.
+	result chan error
+}
+
+// RequestMaintenance asks the controller loop to enter or leave the maintenance state.
+// the function blocks until the controller loop accepts the request or the given context is done.
+func (c *Controller) RequestMaintenance(ctx context.Context, enter bool) error {
+	req := maintenanceRequest{enter: enter, result: make(chan error, 1)}
+	select {
+	case c.maintenanceRequestCh <- req:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-req.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// setMaintenance enters or leaves the maintenance state.
+// entering the maintenance changes nothing but the advertised state, so MariaDB keeps running as it is.
+// leaving the maintenance just unfreezes the state machine. the next loop decides the next state.
+func (c *Controller) setMaintenance(enter bool) error {
+	if !enter {
+		if c.GetState() != StateMaintenance {
+			return ErrNotInMaintenance
+		}
+
+		c.logger.Info("leaving maintenance mode")
+		c.maintenanceMode = false
+		return nil
+	}
+
+	if c.GetState() == StateMaintenance {
+		// already in maintenance.
+		return nil
+	}
+	if !c.transitionTable.CanTransition(c.GetState(), StateMaintenance) {
+		return ErrMaintenanceNotAllowed
+	}
+
+	c.logger.Info("entering maintenance mode")
+	c.maintenanceMode = true
+	c.setStateWithReason(StateMaintenance, journalReasonMaintenance)
+	if err := c.triggerRunOnStateChanges(); err != nil {
+		c.logger.Error("failed to TriggerRunOnStateChanges. transition to fault state.", "error", err, "state", string(c.GetState()))
+		c.maintenanceMode = false
+		c.forceTransitionToFault()
+		return err
+	}
+
+	return nil
+}
+
+// decideNextStateOnMaintenance determines the next state on maintenance state.
+func (c *Controller) decideNextStateOnMaintenance() State {
+	if c.maintenanceMode {
+		// the state machine is frozen while the operator works on this node.
+		return StateMaintenance
+	}
+
+	if c.currentNeighbors.primaryNodeExists() {
+		return StateReplica
+	}
+
+	return StateFault
+}
+
+// triggerRunOnStateChangesToMaintenance transition to maintenance state.
+// the controller keeps MariaDB and nftables as they are, and just reflects the state to BGP.
+func (c *Controller) triggerRunOnStateChangesToMaintenance() error {
+	// [STEP1]: configure bgp route
+	if err := c.advertiseSelfNetIFAddress(); err != nil {
+		return err
+	}
+
+	c.logger.Info("maintenance state handler succeed")
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/bgpserver"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/systemd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetMaintenance_EnterFromReplica(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateReplica)
+
+	err := c.setMaintenance(true)
+	assert.NoError(t, err)
+	assert.Equal(t, StateMaintenance, c.GetState())
+
+	// MariaDB must not be touched in maintenance state.
+	fakeSystemdConnector := c.systemdConnector.(*systemd.FakeSystemdConnector)
+	_, ok := fakeSystemdConnector.Timestamp["KillService"]
+	assert.False(t, ok)
+
+	fakeBgpServerConnector := c.bgpServerConnector.(*bgpserver.FakeBgpServerConnector)
+	prefix := netip.PrefixFrom(netip.MustParseAddr("10.0.0.1"), 32)
+	_, ok = fakeBgpServerConnector.RouteConfigured[prefix]
+	assert.True(t, ok)
+}
+
+func TestSetMaintenance_EnterFromPrimary(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StatePrimary)
+
+	err := c.setMaintenance(true)
+	assert.ErrorIs(t, err, ErrMaintenanceNotAllowed)
+	assert.Equal(t, StatePrimary, c.GetState())
+}
+
+func TestSetMaintenance_LeaveOutOfMaintenance(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateReplica)
+
+	err := c.setMaintenance(false)
+	assert.ErrorIs(t, err, ErrNotInMaintenance)
+}
+
+func TestDecideNextState_MaintenanceIsFrozen(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateFault)
+	assert.NoError(t, c.setMaintenance(true))
+
+	// network partition and unhealthy MariaDB don't change the state.
+	c.currentMariaDBHealth = dbHealthCheckResultNG
+	nextState := c.decideNextState()
+	assert.Equal(t, StateMaintenance, nextState)
+
+	// primary neighbor doesn't change the state too.
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+	nextState = c.decideNextState()
+	assert.Equal(t, StateMaintenance, nextState)
+}
+
+func TestDecideNextStateOnMaintenance_LeaveWithPrimaryNeighbors(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateReplica)
+	assert.NoError(t, c.setMaintenance(true))
+	assert.NoError(t, c.setMaintenance(false))
+
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+	nextState := c.decideNextStateOnMaintenance()
+	assert.Equal(t, StateReplica, nextState)
+}
+
+func TestDecideNextStateOnMaintenance_LeaveWithoutPrimaryNeighbors(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateReplica)
+	assert.NoError(t, c.setMaintenance(true))
+	assert.NoError(t, c.setMaintenance(false))
+
+	c.currentNeighbors[StateReplica] = []neighbor{"10.0.0.2"}
+	nextState := c.decideNextStateOnMaintenance()
+	assert.Equal(t, StateFault, nextState)
+}
+
+func TestDecideNextStateOnFault_WithMaintenanceNeighbors(t *testing.T) {
+	c := _newFakeController()
+	c.currentNeighbors[StateMaintenance] = []neighbor{"10.0.0.2"}
+
+	// the maintenance node is not eligible, so it doesn't block the election.
+	nextState := c.decideNextStateOnFault()
+	assert.Equal(t, StateCandidate, nextState)
+}
+
+func TestIsNetworkParted_WithMaintenanceNeighbors(t *testing.T) {
+	ns := newNeighborSet()
+	ns[StateMaintenance] = []neighbor{"10.0.0.2"}
+
+	// the maintenance node is present on the network.
+	assert.False(t, ns.isNetworkParted())
+}
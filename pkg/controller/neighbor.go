@@ -29,10 +29,11 @@ type neighborSet map[State][]neighbor
 // newNeighborSet initializes the empty NeighborSet.
 func newNeighborSet() neighborSet {
 	return neighborSet{
-		StateFault:     make([]neighbor, 0),
-		StateCandidate: make([]neighbor, 0),
-		StatePrimary:   make([]neighbor, 0),
-		StateReplica:   make([]neighbor, 0),
+		StateFault:       make([]neighbor, 0),
+		StateCandidate:   make([]neighbor, 0),
+		StatePrimary:     make([]neighbor, 0),
+		StateReplica:     make([]neighbor, 0),
+		StateMaintenance: make([]neighbor, 0),
 	}
 }
 
@@ -72,6 +73,39 @@ func (n neighborSet) neighborAddresses() string {
 	return strings.Join(addressesByState, ", ")
 }
 
+// addressesByState returns the addresses of the neighbors by their state.
+// the states that have no neighbor are omitted.
+func (n neighborSet) addressesByState() map[string][]string {
+	addressesByState := make(map[string][]string)
+	for state, neighbors := range n {
+		if len(neighbors) == 0 {
+			continue
+		}
+
+		addrs := make([]string, len(neighbors))
+		for i, neighbor := range neighbors {
+			addrs[i] = string(neighbor)
+		}
+		addressesByState[string(state)] = addrs
+	}
+
+	return addressesByState
+}
+
+// stateOf returns the state that the neighbor advertises.
+// the second return value is false if the neighbor isn't in the set.
+func (n neighborSet) stateOf(target neighbor) (State, bool) {
+	for state, neighbors := range n {
+		for _, nb := range neighbors {
+			if nb == target {
+				return state, true
+			}
+		}
+	}
+
+	return "", false
+}
+
 // primaryNodeExists returns true if the set contains primary-state node(s).
 func (n neighborSet) primaryNodeExists() bool {
 	return len(n[StatePrimary]) != 0
@@ -92,6 +126,12 @@ func (n neighborSet) faultNodeExists() bool {
 	return len(n[StateFault]) != 0
 }
 
+// maintenanceNodeExists returns true if the set contains maintenance-state node(s).
+// the maintenance node is present on the network, but it isn't eligible to be primary.
+func (n neighborSet) maintenanceNodeExists() bool {
+	return len(n[StateMaintenance]) != 0
+}
+
 // anchorNodeExists returns true if the set contains anchor-mode node(s).
 func (n neighborSet) anchorNodeExists() bool {
 	return len(n[StateAnchor]) != 0
@@ -103,6 +143,7 @@ func (n neighborSet) isNetworkParted() bool {
 		n.candidateNodeExists() ||
 		n.replicaNodeExists() ||
 		n.faultNodeExists() ||
+		n.maintenanceNodeExists() ||
 		n.anchorNodeExists() {
 		return false
 	}
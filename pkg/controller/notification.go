@@ -0,0 +1,76 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/notify"
+)
+
+// notifierCloseTimeout is the time limit of delivering the queued events on exit.
+const notifierCloseTimeout = 5 * time.Second
+
+// notify sends the event to the notifiers with the current situation of the controller.
+func (c *Controller) notify(event notify.Event) {
+	if len(c.notifiers) == 0 {
+		return
+	}
+
+	event.Time = time.Now()
+	event.HostAddress = c.hostAddress
+	event.State = string(c.GetState())
+	event.Neighbors = c.currentNeighbors.addressesByState()
+	for _, n := range c.notifiers {
+		n.Notify(event)
+	}
+}
+
+// closeNotifiers waits until the queued events are delivered.
+// the wait is bounded by notifierCloseTimeout in total, so the unreachable endpoint never delays the exit.
+func (c *Controller) closeNotifiers() {
+	ctx, cancel := context.WithTimeout(context.Background(), notifierCloseTimeout)
+	defer cancel()
+
+	// the notifiers are closed in parallel, so a slow endpoint doesn't consume the time of the others.
+	wg := sync.WaitGroup{}
+	for _, n := range c.notifiers {
+		wg.Add(1)
+		go func(n notify.Notifier) {
+			defer wg.Done()
+			n.Close(ctx)
+		}(n)
+	}
+	wg.Wait()
+}
+
+// detectDualPrimary notifies when two or more primaries are visible, including this controller.
+// the decisions may wait for the confirmations, so the event is sent only when the situation begins.
+func (c *Controller) detectDualPrimary() {
+	primaries := make([]neighbor, 0)
+	if c.GetState() == StatePrimary {
+		primaries = append(primaries, neighbor(c.hostAddress))
+	}
+	primaries = append(primaries, c.currentNeighbors[StatePrimary]...)
+
+	dualPrimary := len(primaries) > 1
+	if dualPrimary && !c.dualPrimaryDetected {
+		c.notify(notify.Event{Type: notify.EventDualPrimary, Message: fmt.Sprintf("primaries: %v", primaries)})
+	}
+	c.dualPrimaryDetected = dualPrimary
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"log/slog"
+	"net/netip"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/bgpserver"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/command"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/notify"
+	"github.com/stretchr/testify/assert"
+)
+
+func _newFakeNotifier(c *Controller) *notify.FakeNotifier {
+	fakeNotifier := notify.NewFakeNotifier().(*notify.FakeNotifier)
+	WithNotifiers(fakeNotifier)(c)
+	return fakeNotifier
+}
+
+func TestNotify_StateChanged(t *testing.T) {
+	c := _newFakeController()
+	fakeNotifier := _newFakeNotifier(c)
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+
+	c.setState(StateFault)
+	// the unchanged state is not notified.
+	c.setState(StateFault)
+
+	assert.Len(t, fakeNotifier.Events, 1)
+	event := fakeNotifier.Events[0]
+	assert.Equal(t, notify.EventStateChanged, event.Type)
+	assert.Equal(t, "10.0.0.1", event.HostAddress)
+	assert.Equal(t, string(StateInitial), event.From)
+	assert.Equal(t, string(StateFault), event.To)
+	assert.Equal(t, journalReasonDecided, event.Reason)
+	assert.Equal(t, string(StateFault), event.State)
+	assert.Equal(t, map[string][]string{"primary": {"10.0.0.2"}}, event.Neighbors)
+}
+
+func TestNotify_NeighborsChanged(t *testing.T) {
+	c := _newFakeController()
+	fakeNotifier := _newFakeNotifier(c)
+	fakeBgpServerConnector := c.bgpServerConnector.(*bgpserver.FakeBgpServerConnector)
+	fakeBgpServerConnector.Routes = []bgpserver.Route{
+		{Prefix: netip.MustParsePrefix("10.0.0.2/32"), Community: bgpCommunityReplica},
+	}
+
+	assert.NoError(t, c.preDecideNextStateHandler())
+	// the same neighbors are not notified again.
+	assert.NoError(t, c.preDecideNextStateHandler())
+	assert.Equal(t, []notify.EventType{notify.EventNeighborsChanged}, fakeNotifier.EventTypes())
+
+	fakeBgpServerConnector.Routes = []bgpserver.Route{}
+	assert.NoError(t, c.preDecideNextStateHandler())
+	assert.Equal(t, []notify.EventType{notify.EventNeighborsChanged, notify.EventNeighborsChanged}, fakeNotifier.EventTypes())
+}
+
+func TestDetectDualPrimary(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StatePrimary)
+	fakeNotifier := _newFakeNotifier(c)
+
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+	c.detectDualPrimary()
+	// notified once while the situation continues.
+	c.detectDualPrimary()
+	assert.Equal(t, []notify.EventType{notify.EventDualPrimary}, fakeNotifier.EventTypes())
+
+	c.currentNeighbors[StatePrimary] = []neighbor{}
+	c.detectDualPrimary()
+	assert.False(t, c.dualPrimaryDetected)
+
+	// the replica also finds the primaries.
+	c.setStateWithReason(StateReplica, journalReasonDecided)
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2", "10.0.0.3"}
+	c.detectDualPrimary()
+	assert.Equal(t, notify.EventDualPrimary, fakeNotifier.EventTypes()[len(fakeNotifier.Events)-1])
+}
+
+func TestNotify_WriteTestFailed(t *testing.T) {
+	c := _newFakeController()
+	fakeNotifier := _newFakeNotifier(c)
+	c.mariaDBConnector = mariadb.NewFakeMariaDBFailWriteTestDataConnector()
+
+	assert.NoError(t, c.triggerRunOnStateKeepsPrimary())
+	assert.Equal(t, []notify.EventType{notify.EventWriteTestFailed}, fakeNotifier.EventTypes())
+}
+
+func TestNotify_ReplicationThresholdExhausted(t *testing.T) {
+	c := _newFakeController()
+	fakeNotifier := _newFakeNotifier(c)
+	c.mariaDBConnector = mariadb.NewFakeMariaDBFailedReplicationConnector()
+	c.replicationStatusCheckFailCount = replicationStatusCheckThreshold
+
+	assert.Error(t, c.triggerRunOnStateKeepsReplica())
+	assert.Equal(t, []notify.EventType{notify.EventReplicationThresholdExhausted}, fakeNotifier.EventTypes())
+}
+
+func TestNotify_DryRun(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{}))
+	recorder := command.NewRecorder(logger)
+	c := NewController(
+		logger,
+		WithHostAddress("10.0.0.1"),
+		WithMariaDBConnector(mariadb.NewFakeMariaDBConnector()),
+		WithNotifiers(notify.NewWebhookNotifier(logger, "http://127.0.0.1:1", nil, time.Second, 0)),
+		WithDryRun(recorder),
+	)
+
+	// the event is recorded instead of being sent.
+	c.setState(StateFault)
+	c.closeNotifiers()
+	commands := recorder.Commands(0)
+	assert.Len(t, commands, 1)
+	assert.Equal(t, "notify", commands[0].Component)
+}
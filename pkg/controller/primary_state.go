@@ -16,8 +16,10 @@ package controller
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/sakura-internet/distributed-mariadb-controller/pkg/nftables"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/notify"
 )
 
 const (
@@ -57,6 +59,12 @@ func (c *Controller) triggerRunOnStateChangesToPrimary() error {
 		return fmt.Errorf("dual primary detected")
 	}
 
+	// [STEP0]: fencing the previous primary
+	// that must be done before this controller starts accepting writes.
+	if err := c.fencePreviousPrimary(); err != nil {
+		return err
+	}
+
 	// [STEP1]: setting MariaDB state
 	if err := c.mariaDBConnector.StopReplica(); err != nil {
 		return err
@@ -64,9 +72,19 @@ func (c *Controller) triggerRunOnStateChangesToPrimary() error {
 	if err := c.mariaDBConnector.ResetAllReplicas(); err != nil {
 		return err
 	}
+	// the new epoch must be persisted before the clients write anything.
+	if err := c.beginEpoch(); err != nil {
+		return err
+	}
 	if err := c.syncReadOnlyVariable( /* read_only=0 */ false); err != nil {
 		return err
 	}
+	if err := c.createManagementDatabase(); err != nil {
+		return err
+	}
+	if err := c.writeHeartbeat(); err != nil {
+		return err
+	}
 
 	// [STEP2]: setting nftables state
 	if err := c.acceptDatabaseServiceTraffic(); err != nil {
@@ -80,6 +98,8 @@ func (c *Controller) triggerRunOnStateChangesToPrimary() error {
 
 	// reset the count because the controller is healthy.
 	c.writeTestDataFailCount = 0
+	// this controller is the primary now.
+	c.lastPrimaryNeighbor = ""
 
 	c.logger.Info("primary state handler succeed")
 	return nil
@@ -94,12 +114,18 @@ func (c *Controller) triggerRunOnStateKeepsPrimary() error {
 	if err := c.writeTestDataToMariaDB(); err != nil {
 		c.writeTestDataFailCount++
 		c.logger.Warn("failed to write test data to mariadb", "error", err, "failedCount", c.writeTestDataFailCount)
+		c.notify(notify.Event{
+			Type:    notify.EventWriteTestFailed,
+			Message: fmt.Sprintf("%s (%d/%d)", err, c.writeTestDataFailCount, writeTestDataFailCountThreshold),
+		})
 		// return noerror because this is soft fail
 		return nil
 	}
 
 	// reset the count because the controller is healthy.
 	c.writeTestDataFailCount = 0
+
+	c.tryFailback(time.Now())
 	return nil
 }
 
@@ -135,6 +161,9 @@ func (c *Controller) writeTestDataToMariaDB() error {
 	if err := c.deleteTemporaryRecordOnAliveCheck(); err != nil {
 		return err
 	}
+	if err := c.writeHeartbeat(); err != nil {
+		return err
+	}
 
 	return nil
 }
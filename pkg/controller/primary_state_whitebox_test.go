@@ -15,9 +15,14 @@
 package controller
 
 import (
+	"errors"
+	"net/netip"
 	"testing"
 
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/bgpserver"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/fencing"
 	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/nftables"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -97,3 +102,100 @@ func _mustTurnOffMariaDBReadOnlyVairable(conn *mariadb.FakeMariaDBConnector) fun
 		assert.False(t, conn.ReadOnlyVariable)
 	}
 }
+
+func TestTriggerRunOnStateChangesToPrimary_FenceThePreviousPrimary(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateCandidate)
+	fakeFencer := fencing.NewFakeFencer().(*fencing.FakeFencer)
+	c.fencer = fakeFencer
+	c.lastPrimaryNeighbor = "10.0.0.2"
+
+	err := c.triggerRunOnStateChangesToPrimary()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.2"}, fakeFencer.Fenced)
+	assert.Equal(t, neighbor(""), c.lastPrimaryNeighbor)
+}
+
+func TestTriggerRunOnStateChangesToPrimary_SkipFencingAfterSwitchover(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateReplica)
+	fakeFencer := fencing.NewFakeFencer().(*fencing.FakeFencer)
+	c.fencer = fakeFencer
+
+	fakeBgpServerConnector := c.bgpServerConnector.(*bgpserver.FakeBgpServerConnector)
+	fakeBgpServerConnector.Routes = []bgpserver.Route{
+		{Prefix: netip.MustParsePrefix("10.0.0.2/32"), Community: bgpCommunityPrimary},
+	}
+	assert.NoError(t, c.preDecideNextStateHandler())
+	assert.Equal(t, neighbor("10.0.0.2"), c.lastPrimaryNeighbor)
+
+	// the primary hands over the role and advertises the fault state.
+	fakeBgpServerConnector.Routes = []bgpserver.Route{
+		{Prefix: netip.MustParsePrefix("10.0.0.2/32"), Community: bgpCommunityFault},
+	}
+	assert.NoError(t, c.preDecideNextStateHandler())
+	assert.Equal(t, neighbor(""), c.lastPrimaryNeighbor)
+
+	c.setState(StateCandidate)
+	err := c.triggerRunOnStateChangesToPrimary()
+	assert.NoError(t, err)
+	assert.Empty(t, fakeFencer.Fenced)
+}
+
+func TestTriggerRunOnStateChangesToPrimary_FenceThePrimaryThatDisappeared(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateReplica)
+	fakeFencer := fencing.NewFakeFencer().(*fencing.FakeFencer)
+	c.fencer = fakeFencer
+
+	fakeBgpServerConnector := c.bgpServerConnector.(*bgpserver.FakeBgpServerConnector)
+	fakeBgpServerConnector.Routes = []bgpserver.Route{
+		{Prefix: netip.MustParsePrefix("10.0.0.2/32"), Community: bgpCommunityPrimary},
+		{Prefix: netip.MustParsePrefix("10.0.0.3/32"), Community: bgpCommunityReplica},
+	}
+	assert.NoError(t, c.preDecideNextStateHandler())
+
+	// the primary vanishes without stepping down, so it may be still alive.
+	fakeBgpServerConnector.Routes = fakeBgpServerConnector.Routes[1:]
+	assert.NoError(t, c.preDecideNextStateHandler())
+	assert.Equal(t, neighbor("10.0.0.2"), c.lastPrimaryNeighbor)
+
+	c.setState(StateCandidate)
+	err := c.triggerRunOnStateChangesToPrimary()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.2"}, fakeFencer.Fenced)
+}
+
+func TestTriggerRunOnStateChangesToPrimary_FencingFailed(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateCandidate)
+	fakeFencer := fencing.NewFakeFencer().(*fencing.FakeFencer)
+	fakeFencer.Err = errors.New("failed to power off")
+	c.fencer = fakeFencer
+	c.lastPrimaryNeighbor = "10.0.0.2"
+
+	fakeMariaDBConn := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConn.ReadOnlyVariable = true
+
+	err := c.triggerRunOnStateChangesToPrimary()
+	assert.Error(t, err)
+
+	// the promotion must be blocked before accepting writes.
+	assert.True(t, fakeMariaDBConn.ReadOnlyVariable)
+	fakeNftablesConn := c.nftablesConnector.(*nftables.FakeNftablesConnector)
+	_, ok := fakeNftablesConn.Timestamp["AddRule"]
+	assert.False(t, ok)
+}
+
+func TestTriggerRunOnStateChangesToPrimary_FencingFailedWithBestEffortPolicy(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateCandidate)
+	fakeFencer := fencing.NewFakeFencer().(*fencing.FakeFencer)
+	fakeFencer.Err = errors.New("failed to power off")
+	c.fencer = fakeFencer
+	c.fencingPolicy = FencingPolicyBestEffort
+	c.lastPrimaryNeighbor = "10.0.0.2"
+
+	err := c.triggerRunOnStateChangesToPrimary()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/bgpserver"
+)
+
+const (
+	// bgpCommunityPriorityUpper is the upper 16 bits of the community that carries the node priority.
+	// the lower 16 bits is the priority itself (for example 65100:200 means priority 200).
+	bgpCommunityPriorityUpper = 65100
+	// priorityYieldThreshold is the number of the consecutive loops
+	// that the controller yields the election to the higher-priority neighbor.
+	// the limit prevents the controller from waiting forever for the neighbor that can't be promoted.
+	priorityYieldThreshold = 5
+)
+
+// priorityCommunity returns the community that advertises the given priority.
+func priorityCommunity(priority uint16) bgpserver.Community {
+	return bgpserver.Community(uint32(bgpCommunityPriorityUpper)<<16 | uint32(priority))
+}
+
+// parsePriorityCommunity returns the priority if the community advertises that.
+func parsePriorityCommunity(comm bgpserver.Community) (uint16, bool) {
+	if uint32(comm)>>16 != bgpCommunityPriorityUpper {
+		return 0, false
+	}
+
+	return uint16(uint32(comm) & 0xffff), true
+}
+
+// higherPriorityNeighborExists returns true if a neighbor in the given state has the higher priority than mine.
+// the neighbor that doesn't advertise the priority is regarded as priority 0.
+func (c *Controller) higherPriorityNeighborExists(state State) bool {
+	for _, n := range c.currentNeighbors[state] {
+		if c.neighborPriorities[n] > c.priority {
+			return true
+		}
+	}
+
+	return false
+}
+
+// yieldsToHigherPriorityNeighbor returns true if the controller should give the chance of candidacy
+// to the higher-priority neighbor in the given state.
+// the controller stops yielding after priorityYieldThreshold consecutive loops driven by the ticker.
+func (c *Controller) yieldsToHigherPriorityNeighbor(state State) bool {
+	if !c.higherPriorityNeighborExists(state) {
+		c.priorityYieldCount = 0
+		return false
+	}
+
+	if c.priorityYieldCount >= priorityYieldThreshold {
+		c.logger.Warn("the higher-priority neighbor isn't promoted. stop yielding.", "state", state)
+		return false
+	}
+
+	if !c.eventDriven {
+		c.priorityYieldCount++
+	}
+	c.logger.Info("yielding the candidacy to the higher-priority neighbor", "state", state, "count", c.priorityYieldCount)
+	return true
+}
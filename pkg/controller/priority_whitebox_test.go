@@ -0,0 +1,97 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/bgpserver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriorityCommunity(t *testing.T) {
+	comm := priorityCommunity(200)
+	assert.Equal(t, "65100:200", comm.String())
+
+	priority, ok := parsePriorityCommunity(comm)
+	assert.True(t, ok)
+	assert.Equal(t, uint16(200), priority)
+
+	_, ok = parsePriorityCommunity(bgpCommunityPrimary)
+	assert.False(t, ok)
+}
+
+func TestAdvertiseSelfNetIFAddress_WithPriority(t *testing.T) {
+	c := _newFakeController()
+	WithPriority(200)(c)
+	c.setState(StateFault)
+
+	assert.NoError(t, c.advertiseSelfNetIFAddress())
+
+	fakeBgpServerConnector := c.bgpServerConnector.(*bgpserver.FakeBgpServerConnector)
+	route := fakeBgpServerConnector.AdvertisedRoutes[netip.MustParsePrefix("10.0.0.1/32")]
+	assert.Equal(t, bgpCommunityFault, route.Community)
+	assert.Equal(t, []bgpserver.Community{priorityCommunity(200)}, route.AdditionalCommunities)
+}
+
+func TestPreDecideNextStateHandler_NeighborPriority(t *testing.T) {
+	c := _newFakeController()
+	fakeBgpServerConnector := c.bgpServerConnector.(*bgpserver.FakeBgpServerConnector)
+	fakeBgpServerConnector.Routes = []bgpserver.Route{
+		{Prefix: netip.MustParsePrefix("10.0.0.2/32"), Community: bgpCommunityFault},
+		{Prefix: netip.MustParsePrefix("10.0.0.2/32"), Community: priorityCommunity(200)},
+	}
+
+	assert.NoError(t, c.preDecideNextStateHandler())
+	assert.Equal(t, []neighbor{"10.0.0.2"}, c.currentNeighbors[StateFault])
+	assert.Equal(t, uint16(200), c.neighborPriorities["10.0.0.2"])
+}
+
+func TestDecideNextStateOnFault_YieldsToHigherPriority(t *testing.T) {
+	c := _newFakeController()
+	WithPriority(100)(c)
+	c.setState(StateFault)
+	c.currentNeighbors[StateFault] = []neighbor{"10.0.0.2"}
+	c.neighborPriorities["10.0.0.2"] = 200
+
+	for i := 0; i < priorityYieldThreshold; i++ {
+		assert.Equal(t, StateFault, c.decideNextStateOnFault())
+	}
+	// the higher-priority neighbor didn't become candidate in time.
+	assert.Equal(t, StateCandidate, c.decideNextStateOnFault())
+}
+
+func TestDecideNextStateOnFault_LowerPriorityNeighbor(t *testing.T) {
+	c := _newFakeController()
+	WithPriority(200)(c)
+	c.setState(StateFault)
+	c.currentNeighbors[StateFault] = []neighbor{"10.0.0.2"}
+	c.neighborPriorities["10.0.0.2"] = 100
+
+	assert.Equal(t, StateCandidate, c.decideNextStateOnFault())
+}
+
+func TestDecideNextStateOnReplica_YieldsToHigherPriority(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateReplica)
+	c.currentNeighbors[StateReplica] = []neighbor{"10.0.0.2"}
+	c.neighborPriorities["10.0.0.2"] = 1
+
+	assert.Equal(t, StateReplica, c.decideNextStateOnReplica())
+
+	delete(c.neighborPriorities, "10.0.0.2")
+	assert.Equal(t, StateCandidate, c.decideNextStateOnReplica())
+}
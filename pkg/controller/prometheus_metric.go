@@ -17,6 +17,7 @@ package controller
 import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/healthcheck"
 )
 
 var (
@@ -38,6 +39,71 @@ var (
 		},
 		[]string{"state"},
 	)
+	// dbControllerPendingTransitionGaugeVec is the gauge-vec metric in prometheus
+	// that holds the confirmations of the transition that waits for the consecutive observations.
+	dbControllerPendingTransitionGaugeVec = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "edb_db_controller_pending_transition_confirmations",
+			Help: "the confirmations of the pending state transition of db-controller",
+		},
+		[]string{"from", "to"},
+	)
+	// dbControllerQuorumVisibleWeightGauge is the gauge metric in prometheus
+	// that holds the total weight of the visible quorum members.
+	dbControllerQuorumVisibleWeightGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "edb_db_controller_quorum_visible_weight",
+			Help: "the total weight of the quorum members visible from db-controller",
+		},
+	)
+	// dbControllerQuorumTotalWeightGauge is the gauge metric in prometheus
+	// that holds the total weight of all quorum members.
+	dbControllerQuorumTotalWeightGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "edb_db_controller_quorum_total_weight",
+			Help: "the total weight of all quorum members of db-controller",
+		},
+	)
+	// dbControllerHeartbeatLagGauge is the gauge metric in prometheus
+	// that holds the replication lag measured with the heartbeat table.
+	dbControllerHeartbeatLagGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "edb_db_controller_heartbeat_lag_seconds",
+			Help: "the replication lag of db-controller measured with the heartbeat table",
+		},
+	)
+	// dbControllerClusterEpochGauge is the gauge metric in prometheus
+	// that holds the highest cluster epoch observed by db-controller.
+	dbControllerClusterEpochGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "edb_db_controller_cluster_epoch",
+			Help: "the highest cluster epoch observed by db-controller",
+		},
+	)
+	// dbControllerReplicaReadableGauge is the gauge metric in prometheus
+	// that is 1 while the replica serves the read traffic.
+	dbControllerReplicaReadableGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "edb_db_controller_replica_readable",
+			Help: "1 if the replica of db-controller serves the read traffic",
+		},
+	)
+	// dbControllerDrainKilledSessionsCounter is the counter metric in prometheus
+	// that holds the number of the client sessions killed after the draining.
+	dbControllerDrainKilledSessionsCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "edb_db_controller_drain_killed_sessions_total",
+			Help: "the number of the client sessions that db-controller killed after the grace period of the draining",
+		},
+	)
+	// dbControllerDivergedGauge is the gauge metric in prometheus
+	// that is 1 while the local MariaDB has diverged from the primary.
+	dbControllerDivergedGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "edb_db_controller_diverged",
+			Help: "1 if the local MariaDB has the errant transactions that the primary never saw",
+		},
+	)
 )
 
 func init() {
@@ -46,6 +112,7 @@ func init() {
 	dbControllerStateGaugeVec.WithLabelValues(string(StateCandidate)).Set(0)
 	dbControllerStateGaugeVec.WithLabelValues(string(StateReplica)).Set(0)
 	dbControllerStateGaugeVec.WithLabelValues(string(StatePrimary)).Set(0)
+	dbControllerStateGaugeVec.WithLabelValues(string(StateMaintenance)).Set(0)
 }
 
 func NewPrometheusMetricRegistry() *prometheus.Registry {
@@ -59,6 +126,16 @@ func NewPrometheusMetricRegistry() *prometheus.Registry {
 		// db-controller
 		dbControllerStateGaugeVec,
 		dbControllerStateTransitionCounterVec,
+		dbControllerDivergedGauge,
+		dbControllerPendingTransitionGaugeVec,
+		dbControllerQuorumVisibleWeightGauge,
+		dbControllerQuorumTotalWeightGauge,
+		dbControllerHeartbeatLagGauge,
+		dbControllerClusterEpochGauge,
+		dbControllerReplicaReadableGauge,
+		dbControllerDrainKilledSessionsCounter,
 	)
+	// storage watchdog
+	reg.MustRegister(healthcheck.StorageCollectors()...)
 	return reg
 }
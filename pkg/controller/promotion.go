@@ -0,0 +1,126 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+)
+
+// defaultPromotionGTIDWaitTimeout is the default time limit for waiting the relay log is applied.
+const defaultPromotionGTIDWaitTimeout = 10 * time.Second
+
+// PromotionTimeoutPolicy specifies how the candidate deals with the timeout of waiting the relay log is applied.
+type PromotionTimeoutPolicy string
+
+const (
+	// PromotionTimeoutPolicyStayCandidate keeps candidate state until the relay log is applied.
+	PromotionTimeoutPolicyStayCandidate PromotionTimeoutPolicy = "stay-candidate"
+	// PromotionTimeoutPolicyPromote promotes the candidate even if the relay log isn't applied.
+	PromotionTimeoutPolicyPromote PromotionTimeoutPolicy = "promote"
+)
+
+// readyToBePromotedToPrimary returns true when the controller satisfies the conditions to be promoted to primary state.
+func (c *Controller) readyToBePromotedToPrimary() readyToPrimaryJudge {
+	status, err := c.mariaDBConnector.ShowReplicationStatus()
+	if err != nil {
+		c.logger.Debug("failed to show replication status", "error", err)
+		return readytoPrimaryJudgeNG
+	}
+
+	readMasterLogPos, ok := status[mariadb.ReplicationStatusReadMasterLogPos]
+	if !ok {
+		// the replication has never been configured, like the bootstrap of the cluster,
+		// or it has been discarded on entering fault state.
+		// however, the replication started in replica state must not be lost silently.
+		if c.replicationConfigured {
+			c.logger.Warn("the replication status is empty although the replication has been started", "primary", c.replicationSource)
+			return readytoPrimaryJudgeNG
+		}
+		return readytoPrimaryJudgeOK
+	}
+
+	if c.promotionMaxLag > 0 {
+		lag, known := replicationLag(status)
+		if !known {
+			// the lag is unknown while the replication is broken. use the last observed one.
+			lag, known = c.lastReplicationLag, c.lastReplicationLagKnown
+		}
+		// the heartbeat lag covers the transactions that the IO thread hasn't received yet.
+		if hbLag, hbKnown := c.HeartbeatLag(); hbKnown && (!known || hbLag > lag) {
+			lag, known = hbLag, true
+		}
+		if known && lag > c.promotionMaxLag {
+			c.logger.Info("the replication lag exceeds the limit", "lag", lag, "limit", c.promotionMaxLag)
+			return readytoPrimaryJudgeNG
+		}
+	}
+
+	ioPos, err := mariadb.ParseGTIDSet(status[mariadb.ReplicationStatusGtidIOPos])
+	if err == nil && len(ioPos) != 0 && status[mariadb.ReplicationStatusSlaveSQLRunning] == mariadb.ReplicationStatusSlaveSQLRunningYes {
+		return c.waitForRelayLogApplied(ioPos)
+	}
+
+	// the GTID can't be waited, so we compare the binlog positions instead.
+	if readMasterLogPos == status[mariadb.ReplicationStatusExecMasterLogPos] &&
+		status[mariadb.ReplicationStatusMasterLogFile] == status[mariadb.ReplicationStatusRelayMasterLogFile] {
+		return readytoPrimaryJudgeOK
+	}
+
+	return readytoPrimaryJudgeNG
+}
+
+// waitForRelayLogApplied waits until the SQL thread applies the transactions received by the IO thread.
+func (c *Controller) waitForRelayLogApplied(ioPos mariadb.GTIDSet) readyToPrimaryJudge {
+	err := c.mariaDBConnector.MasterGTIDWait(ioPos, c.promotionGTIDWaitTimeout)
+	if err == nil {
+		return readytoPrimaryJudgeOK
+	}
+
+	if !errors.Is(err, mariadb.ErrMasterGTIDWaitTimeout) {
+		c.logger.Warn("failed to wait for the relay log to be applied", "error", err)
+		return readytoPrimaryJudgeNG
+	}
+
+	if c.promotionTimeoutPolicy == PromotionTimeoutPolicyPromote {
+		c.logger.Warn("the relay log isn't applied in time but promote anyway because of the policy", "gtid", ioPos.String())
+		return readytoPrimaryJudgeOK
+	}
+
+	c.logger.Info("the relay log isn't applied in time. staying candidate state.", "gtid", ioPos.String())
+	return readytoPrimaryJudgeNG
+}
+
+// replicationLag returns Seconds_Behind_Master of the status.
+// the lag is unknown (NULL) while the replication threads are not running.
+func replicationLag(status mariadb.ReplicationStatus) (time.Duration, bool) {
+	sec, err := strconv.Atoi(status[mariadb.ReplicationStatusSecondsBehindMaster])
+	if err != nil {
+		return 0, false
+	}
+
+	return time.Duration(sec) * time.Second, true
+}
+
+// observeReplicationLag records the lag for the promotion after the replication is broken.
+func (c *Controller) observeReplicationLag(status mariadb.ReplicationStatus) {
+	if lag, ok := replicationLag(status); ok {
+		c.lastReplicationLag = lag
+		c.lastReplicationLagKnown = true
+	}
+}
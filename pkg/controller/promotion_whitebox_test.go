@@ -0,0 +1,130 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/stretchr/testify/assert"
+)
+
+func _newPromotionController(status mariadb.ReplicationStatus) (*Controller, *mariadb.FakeMariaDBConnector) {
+	c := _newFakeController()
+	c.setState(StateCandidate)
+	fakeMariaDBConn := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConn.ReplicationStatusOverride = status
+	return c, fakeMariaDBConn
+}
+
+func TestReadyToBePromotedToPrimary_NoReplication(t *testing.T) {
+	c, _ := _newPromotionController(nil)
+	assert.Equal(t, readytoPrimaryJudgeOK, c.readyToBePromotedToPrimary())
+
+	// the replication started in replica state has been lost.
+	c.lastPrimaryNeighbor = "10.0.0.2"
+	c.replicationSource = "10.0.0.2"
+	c.replicationConfigured = true
+	assert.Equal(t, readytoPrimaryJudgeNG, c.readyToBePromotedToPrimary())
+}
+
+func TestReadyToBePromotedToPrimary_NoReplicationAfterFault(t *testing.T) {
+	c, _ := _newPromotionController(nil)
+	c.setState(StateReplica)
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+	assert.NoError(t, c.startReplicationFrom("10.0.0.2"))
+	c.lastPrimaryNeighbor = "10.0.0.2"
+
+	// replica -> fault -> candidate. the replication is discarded with MariaDB.
+	c.setState(StateFault)
+	assert.NoError(t, c.triggerRunOnStateChangesToFault())
+	c.setState(StateCandidate)
+	assert.NoError(t, c.triggerRunOnStateChangesToCandidate())
+
+	assert.Equal(t, readytoPrimaryJudgeOK, c.readyToBePromotedToPrimary())
+	// the previous primary is still fenced on the promotion.
+	assert.Equal(t, neighbor("10.0.0.2"), c.lastPrimaryNeighbor)
+}
+
+func TestReadyToBePromotedToPrimary_GTIDWait(t *testing.T) {
+	c, fakeMariaDBConn := _newPromotionController(mariadb.ReplicationStatus{
+		mariadb.ReplicationStatusReadMasterLogPos: "200",
+		mariadb.ReplicationStatusExecMasterLogPos: "100",
+		mariadb.ReplicationStatusGtidIOPos:        "0-2-100",
+	})
+
+	assert.Equal(t, readytoPrimaryJudgeOK, c.readyToBePromotedToPrimary())
+	_, called := fakeMariaDBConn.Timestamp["MasterGTIDWait"]
+	assert.True(t, called)
+}
+
+func TestReadyToBePromotedToPrimary_GTIDWaitTimeout(t *testing.T) {
+	c, fakeMariaDBConn := _newPromotionController(mariadb.ReplicationStatus{
+		mariadb.ReplicationStatusReadMasterLogPos: "200",
+		mariadb.ReplicationStatusGtidIOPos:        "0-2-100",
+	})
+	fakeMariaDBConn.MasterGTIDWaitErr = mariadb.ErrMasterGTIDWaitTimeout
+
+	assert.Equal(t, readytoPrimaryJudgeNG, c.readyToBePromotedToPrimary())
+
+	WithPromotionGate(0, time.Second, PromotionTimeoutPolicyPromote)(c)
+	assert.Equal(t, readytoPrimaryJudgeOK, c.readyToBePromotedToPrimary())
+}
+
+func TestReadyToBePromotedToPrimary_BinlogPosition(t *testing.T) {
+	c, _ := _newPromotionController(mariadb.ReplicationStatus{
+		mariadb.ReplicationStatusSlaveSQLRunning:    "No",
+		mariadb.ReplicationStatusReadMasterLogPos:   "200",
+		mariadb.ReplicationStatusExecMasterLogPos:   "100",
+		mariadb.ReplicationStatusMasterLogFile:      "mysql-bin.000001",
+		mariadb.ReplicationStatusRelayMasterLogFile: "mysql-bin.000001",
+		mariadb.ReplicationStatusGtidIOPos:          "0-2-100",
+	})
+	assert.Equal(t, readytoPrimaryJudgeNG, c.readyToBePromotedToPrimary())
+
+	c.mariaDBConnector.(*mariadb.FakeMariaDBConnector).ReplicationStatusOverride[mariadb.ReplicationStatusExecMasterLogPos] = "200"
+	assert.Equal(t, readytoPrimaryJudgeOK, c.readyToBePromotedToPrimary())
+}
+
+func TestReadyToBePromotedToPrimary_MaxLag(t *testing.T) {
+	c, _ := _newPromotionController(mariadb.ReplicationStatus{
+		mariadb.ReplicationStatusReadMasterLogPos:    "200",
+		mariadb.ReplicationStatusGtidIOPos:           "0-2-100",
+		mariadb.ReplicationStatusSecondsBehindMaster: "120",
+	})
+	WithPromotionGate(time.Minute, time.Second, PromotionTimeoutPolicyStayCandidate)(c)
+	assert.Equal(t, readytoPrimaryJudgeNG, c.readyToBePromotedToPrimary())
+
+	// the last observed lag is used while the replication is broken.
+	c.mariaDBConnector.(*mariadb.FakeMariaDBConnector).ReplicationStatusOverride[mariadb.ReplicationStatusSecondsBehindMaster] = "NULL"
+	c.observeReplicationLag(mariadb.ReplicationStatus{mariadb.ReplicationStatusSecondsBehindMaster: "90"})
+	assert.Equal(t, readytoPrimaryJudgeNG, c.readyToBePromotedToPrimary())
+
+	c.observeReplicationLag(mariadb.ReplicationStatus{mariadb.ReplicationStatusSecondsBehindMaster: "3"})
+	assert.Equal(t, readytoPrimaryJudgeOK, c.readyToBePromotedToPrimary())
+}
+
+func TestPreDecideNextStateHandler_ReadyToPrimaryOnlyInCandidate(t *testing.T) {
+	c, _ := _newPromotionController(nil)
+	c.setState(StateReplica)
+
+	assert.NoError(t, c.preDecideNextStateHandler())
+	assert.Equal(t, readytoPrimaryJudgeNG, c.readyToPrimary)
+
+	c.setState(StateCandidate)
+	assert.NoError(t, c.preDecideNextStateHandler())
+	assert.Equal(t, readytoPrimaryJudgeOK, c.readyToPrimary)
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// QuorumMember is a voter of the quorum.
+// the members are the anchors and the database nodes, including this controller itself.
+type QuorumMember struct {
+	Address string
+	Weight  uint
+}
+
+// ParseQuorumMembers parses the comma-separated members like "192.0.2.1=1,10.0.0.1=1,10.0.0.2=1".
+// the weight can be omitted, that is regarded as 1.
+// the empty string is parsed into no member, that disables the quorum.
+func ParseQuorumMembers(s string) ([]QuorumMember, error) {
+	members := make([]QuorumMember, 0)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		addrWeight := strings.Split(part, "=")
+		if len(addrWeight) > 2 {
+			return nil, fmt.Errorf("invalid quorum member: %s", part)
+		}
+		addr, err := netip.ParseAddr(addrWeight[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid quorum member %s: %w", part, err)
+		}
+		weight := uint64(1)
+		if len(addrWeight) == 2 {
+			weight, err = strconv.ParseUint(addrWeight[1], 10, 32)
+			if err != nil || weight == 0 {
+				return nil, fmt.Errorf("invalid quorum member %s: the weight must be positive", part)
+			}
+		}
+		for _, m := range members {
+			if m.Address == addr.String() {
+				return nil, fmt.Errorf("duplicated quorum member: %s", addr)
+			}
+		}
+		members = append(members, QuorumMember{Address: addr.String(), Weight: uint(weight)})
+	}
+
+	return members, nil
+}
+
+// quorumWeights returns the total weight of the visible members and all members.
+// this controller itself is always visible.
+func (c *Controller) quorumWeights() (visible uint, total uint) {
+	visibleNeighbors := make(map[neighbor]bool)
+	for _, neighbors := range c.currentNeighbors {
+		for _, n := range neighbors {
+			visibleNeighbors[n] = true
+		}
+	}
+
+	for _, m := range c.quorumMembers {
+		total += m.Weight
+		if m.Address == c.hostAddress || visibleNeighbors[neighbor(m.Address)] {
+			visible += m.Weight
+		}
+	}
+
+	return visible, total
+}
+
+// hasQuorum returns true if the strict majority of the voters is visible.
+// without the configured members, the controller has the quorum as long as any neighbor is visible.
+func (c *Controller) hasQuorum() bool {
+	if len(c.quorumMembers) == 0 {
+		return !c.currentNeighbors.isNetworkParted()
+	}
+
+	visible, total := c.quorumWeights()
+	dbControllerQuorumVisibleWeightGauge.Set(float64(visible))
+	dbControllerQuorumTotalWeightGauge.Set(float64(total))
+
+	return visible*2 > total
+}
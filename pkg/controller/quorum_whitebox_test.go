@@ -0,0 +1,97 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuorumMembers(t *testing.T) {
+	members, err := ParseQuorumMembers("192.0.2.1=2, 10.0.0.1,10.0.0.2=1")
+	assert.NoError(t, err)
+	assert.Equal(t, []QuorumMember{
+		{Address: "192.0.2.1", Weight: 2},
+		{Address: "10.0.0.1", Weight: 1},
+		{Address: "10.0.0.2", Weight: 1},
+	}, members)
+
+	members, err = ParseQuorumMembers("")
+	assert.NoError(t, err)
+	assert.Empty(t, members)
+
+	for _, invalid := range []string{"host=1", "10.0.0.1=0", "10.0.0.1=x", "10.0.0.1=1=1", "10.0.0.1,10.0.0.1"} {
+		_, err := ParseQuorumMembers(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func _newQuorumController() *Controller {
+	c := _newFakeController()
+	WithQuorum([]QuorumMember{
+		{Address: "192.0.2.1", Weight: 1},
+		{Address: "192.0.2.2", Weight: 1},
+		{Address: "10.0.0.1", Weight: 1},
+		{Address: "10.0.0.2", Weight: 1},
+	})(c)
+	return c
+}
+
+func TestHasQuorum(t *testing.T) {
+	c := _newQuorumController()
+
+	// self and one anchor are only the half.
+	c.currentNeighbors[StateAnchor] = []neighbor{"192.0.2.1"}
+	assert.False(t, c.hasQuorum())
+
+	c.currentNeighbors[StateAnchor] = []neighbor{"192.0.2.1", "192.0.2.2"}
+	assert.True(t, c.hasQuorum())
+
+	// the unknown neighbor doesn't vote.
+	c.currentNeighbors[StateAnchor] = []neighbor{"192.0.2.1"}
+	c.currentNeighbors[StateFault] = []neighbor{"10.0.0.9"}
+	assert.False(t, c.hasQuorum())
+}
+
+func TestHasQuorum_WithoutMembers(t *testing.T) {
+	c := _newFakeController()
+	assert.False(t, c.hasQuorum())
+
+	c.currentNeighbors[StateAnchor] = []neighbor{"192.0.2.1"}
+	assert.True(t, c.hasQuorum())
+}
+
+func TestDecideNextState_PrimaryLosesQuorum(t *testing.T) {
+	c := _newQuorumController()
+	c.setState(StatePrimary)
+	c.currentMariaDBHealth = dbHealthCheckResultOK
+	c.currentNeighbors[StateAnchor] = []neighbor{"192.0.2.1", "192.0.2.2"}
+	assert.Equal(t, StatePrimary, c.decideNextState())
+
+	// a partial view of the network.
+	c.currentNeighbors[StateAnchor] = []neighbor{"192.0.2.1"}
+	assert.Equal(t, StateFault, c.decideNextState())
+}
+
+func TestCanBePromotedToPrimary(t *testing.T) {
+	c := _newQuorumController()
+	c.currentNeighbors[StateAnchor] = []neighbor{"192.0.2.1"}
+	c.currentNeighbors[StateFault] = []neighbor{"10.0.0.2"}
+	assert.True(t, c.canBePromotedToPrimary())
+
+	c.currentNeighbors[StateFault] = []neighbor{}
+	assert.False(t, c.canBePromotedToPrimary())
+}
@@ -0,0 +1,215 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+)
+
+const (
+	// defaultReconcileTimeout is the default time limit for waiting the BGP neighbors on startup.
+	defaultReconcileTimeout = 10 * time.Second
+	// reconcileNeighborCheckInterval is the interval of checking the BGP neighbors on startup.
+	reconcileNeighborCheckInterval = 500 * time.Millisecond
+)
+
+// reconcile inspects the existing MariaDB and adopts the last state if that is still safe.
+// if the controller doesn't adopt, it starts from initial state as usual,
+// so the fault state handler will stop MariaDB.
+func (c *Controller) reconcile(ctx context.Context) {
+	if !c.adoptRunningMariaDB {
+		return
+	}
+
+	last, err := c.lastJournalEntry()
+	if err != nil {
+		c.logger.Warn("failed to read the journal. skip the reconciliation.", "error", err)
+		return
+	}
+	if last == nil {
+		c.logger.Info("nothing to adopt on startup. the journal is empty.")
+		return
+	}
+	lastState := last.To
+	if lastState != StatePrimary && lastState != StateReplica {
+		c.logger.Info("nothing to adopt on startup", "last state", lastState)
+		return
+	}
+
+	if err := c.systemdConnector.CheckServiceStatus(mariadb.SystemdServiceName); err != nil {
+		c.logger.Info("MariaDB is not running. nothing to adopt on startup", "last state", lastState)
+		return
+	}
+
+	if err := c.waitForNeighbors(ctx); err != nil {
+		c.logger.Warn("failed to observe the neighbors. skip the reconciliation.", "error", err)
+		return
+	}
+
+	// the primary detached on shutdown has stopped the writes by itself.
+	detached := lastState == StatePrimary && last.Reason == journalReasonDetached
+	if err := c.adopt(lastState, detached); err != nil {
+		c.logger.Warn("the running MariaDB isn't adopted", "last state", lastState, "reason", err)
+		return
+	}
+
+	c.logger.Info("adopted the running MariaDB", "state", c.GetState(), "last gtid", last.GTID)
+}
+
+// waitForNeighbors waits until the BGP neighbors are visible.
+// the routes of the neighbors are not received just after the bgp server started.
+func (c *Controller) waitForNeighbors(ctx context.Context) error {
+	deadline := time.Now().Add(c.reconcileTimeout)
+	for {
+		if err := c.preDecideNextStateHandler(); err != nil {
+			return err
+		}
+		if c.hasQuorum() {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return errors.New("the quorum is not visible")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(reconcileNeighborCheckInterval):
+		}
+	}
+}
+
+// adopt takes over the running MariaDB as the given state.
+// the function returns an error if the current situation is not safe for the state.
+// detached is true if the primary has stopped the writes on shutdown, then the writes are resumed.
+func (c *Controller) adopt(state State, detached bool) error {
+	if !c.transitionTable.CanTransition(c.GetState(), state) {
+		return fmt.Errorf("the transition table has no edge from %s to %s", c.GetState(), state)
+	}
+	if c.currentMariaDBHealth == dbHealthCheckResultNG {
+		return errors.New("MariaDB is not healthy")
+	}
+
+	accepted, err := c.isDatabaseServiceTrafficAccepted()
+	if err != nil {
+		return err
+	}
+	readOnly := c.mariaDBConnector.IsReadOnly()
+
+	switch state {
+	case StatePrimary:
+		if c.currentNeighbors.primaryNodeExists() || c.currentNeighbors.candidateNodeExists() {
+			return errors.New("another primary or candidate exists")
+		}
+		if detached {
+			if !readOnly || accepted {
+				return fmt.Errorf("MariaDB is not detached as primary (read_only=%t, accepted=%t)", readOnly, accepted)
+			}
+		} else if readOnly || !accepted {
+			return fmt.Errorf("MariaDB is not serving as primary (read_only=%t, accepted=%t)", readOnly, accepted)
+		}
+	case StateReplica:
+		if len(c.currentNeighbors[StatePrimary]) != 1 {
+			return fmt.Errorf("the primary neighbor is not unique (%d primaries)", len(c.currentNeighbors[StatePrimary]))
+		}
+		if !readOnly || accepted {
+			return fmt.Errorf("MariaDB is not serving as replica (read_only=%t, accepted=%t)", readOnly, accepted)
+		}
+		status, err := c.mariaDBConnector.ShowReplicationStatus()
+		if err != nil {
+			return err
+		}
+		if !c.checkRequiredReplicationStatusIsOK(status) {
+			return errors.New("the replication is not running")
+		}
+		primaryNode := string(c.currentNeighbors[StatePrimary][0])
+		if status[mariadb.ReplicationStatusMasterHost] != primaryNode {
+			return fmt.Errorf("the replication source %s is not the primary neighbor %s", status[mariadb.ReplicationStatusMasterHost], primaryNode)
+		}
+	default:
+		return fmt.Errorf("the state %s can't be adopted", state)
+	}
+
+	if state == StatePrimary {
+		if err := c.resumeEpoch(); err != nil {
+			return err
+		}
+	}
+	if detached {
+		if err := c.syncReadOnlyVariable( /* read_only=0 */ false); err != nil {
+			return err
+		}
+		if err := c.acceptDatabaseServiceTraffic(); err != nil {
+			return err
+		}
+	}
+	if state == StateReplica {
+		c.replicationSource = c.currentNeighbors[StatePrimary][0]
+		c.replicationConfigured = true
+	}
+	c.setStateWithReason(state, journalReasonAdopted)
+	if err := c.advertiseSelfNetIFAddress(); err != nil {
+		c.logger.Error("failed to advertise self-address while adopting. transition to fault state.", "error", err)
+		c.forceTransitionToFault()
+		return err
+	}
+
+	return nil
+}
+
+// isDatabaseServiceTrafficAccepted returns true if the nftables chain accepts the database traffic as primary.
+// the replica that serves the read traffic also has the accept rule, but that is followed by the reject rule.
+func (c *Controller) isDatabaseServiceTrafficAccepted() (bool, error) {
+	rules, err := c.nftablesConnector.ListRules(c.dbAclChainName)
+	if err != nil {
+		return false, err
+	}
+
+	accepted := false
+	for _, rule := range rules {
+		if !ruleMatchesDstPort(rule, c.dbServingPort) {
+			continue
+		}
+		if strings.HasSuffix(rule, "reject") {
+			return false, nil
+		}
+		if strings.HasSuffix(rule, "accept") {
+			accepted = true
+		}
+	}
+
+	return accepted, nil
+}
+
+// ruleMatchesDstPort returns true if the nftables rule matches the given destination port.
+// the port is compared as a whole token, so "dport 3306" doesn't match "dport 33060".
+func ruleMatchesDstPort(rule string, port uint16) bool {
+	fields := strings.Fields(rule)
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] == "dport" && fields[i+1] == strconv.Itoa(int(port)) {
+			return true
+		}
+	}
+
+	return false
+}
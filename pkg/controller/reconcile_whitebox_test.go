@@ -0,0 +1,144 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"net/netip"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/bgpserver"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/nftables"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/systemd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdopt_Primary(t *testing.T) {
+	c := _newFakeController()
+	c.currentNeighbors[StateReplica] = []neighbor{"10.0.0.2"}
+	assert.NoError(t, c.acceptDatabaseServiceTraffic())
+
+	err := c.adopt(StatePrimary, false)
+	assert.NoError(t, err)
+	assert.Equal(t, StatePrimary, c.GetState())
+
+	fakeBgpServerConnector := c.bgpServerConnector.(*bgpserver.FakeBgpServerConnector)
+	prefix := netip.PrefixFrom(netip.MustParseAddr("10.0.0.1"), 32)
+	_, ok := fakeBgpServerConnector.RouteConfigured[prefix]
+	assert.True(t, ok)
+}
+
+func TestAdopt_PrimaryWithAnotherPrimary(t *testing.T) {
+	c := _newFakeController()
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+	assert.NoError(t, c.acceptDatabaseServiceTraffic())
+
+	err := c.adopt(StatePrimary, false)
+	assert.Error(t, err)
+	assert.Equal(t, StateInitial, c.GetState())
+}
+
+func TestAdopt_PrimaryWithReadOnly(t *testing.T) {
+	c := _newFakeController()
+	c.currentNeighbors[StateReplica] = []neighbor{"10.0.0.2"}
+	assert.NoError(t, c.acceptDatabaseServiceTraffic())
+	c.mariaDBConnector.(*mariadb.FakeMariaDBConnector).ReadOnlyVariable = true
+
+	err := c.adopt(StatePrimary, false)
+	assert.Error(t, err)
+	assert.Equal(t, StateInitial, c.GetState())
+}
+
+func TestAdopt_Replica(t *testing.T) {
+	c := _newFakeController()
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+	assert.NoError(t, c.rejectDatabaseServiceTraffic())
+
+	fakeMariaDBConn := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConn.ReadOnlyVariable = true
+	assert.NoError(t, fakeMariaDBConn.ChangeMasterTo(mariadb.MasterInstance{Host: "10.0.0.2"}))
+
+	err := c.adopt(StateReplica, false)
+	assert.NoError(t, err)
+	assert.Equal(t, StateReplica, c.GetState())
+
+	// the adopted replication must not be lost silently on the promotion.
+	assert.True(t, c.replicationConfigured)
+	fakeMariaDBConn.ReplicationStatusOverride = mariadb.ReplicationStatus{}
+	fakeMariaDBConn.MasterConfig = mariadb.MasterInstance{}
+	c.setState(StateCandidate)
+	assert.Equal(t, readytoPrimaryJudgeNG, c.readyToBePromotedToPrimary())
+}
+
+func TestAdopt_ReplicaOfAnotherSource(t *testing.T) {
+	c := _newFakeController()
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+	assert.NoError(t, c.rejectDatabaseServiceTraffic())
+
+	fakeMariaDBConn := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConn.ReadOnlyVariable = true
+	assert.NoError(t, fakeMariaDBConn.ChangeMasterTo(mariadb.MasterInstance{Host: "10.0.0.3"}))
+
+	err := c.adopt(StateReplica, false)
+	assert.Error(t, err)
+	assert.Equal(t, StateInitial, c.GetState())
+}
+
+func TestIsDatabaseServiceTrafficAccepted_OtherPort(t *testing.T) {
+	c := _newFakeController()
+	assert.NoError(t, c.rejectDatabaseServiceTraffic())
+
+	// the accept rule of the X protocol port must not be regarded as the one of the database service.
+	fakeNftablesConn := c.nftablesConnector.(*nftables.FakeNftablesConnector)
+	fakeNftablesConn.Rules["dummy-chain-name"] = append([]string{
+		"iifname dummy-global-interface-name tcp dport 33060 accept",
+	}, fakeNftablesConn.Rules["dummy-chain-name"]...)
+	accepted, err := c.isDatabaseServiceTrafficAccepted()
+	assert.NoError(t, err)
+	assert.False(t, accepted)
+
+	fakeNftablesConn.Rules["dummy-chain-name"] = []string{"iifname dummy-global-interface-name tcp dport 33060 accept"}
+	accepted, err = c.isDatabaseServiceTrafficAccepted()
+	assert.NoError(t, err)
+	assert.False(t, accepted)
+}
+
+func TestRuleMatchesDstPort(t *testing.T) {
+	assert.True(t, ruleMatchesDstPort("iifname eth0 tcp dport 3306 accept", 3306))
+	assert.True(t, ruleMatchesDstPort("tcp dport 3306", 3306))
+	assert.False(t, ruleMatchesDstPort("iifname eth0 tcp dport 33060 accept", 3306))
+	assert.False(t, ruleMatchesDstPort("iifname eth0 tcp dport 3307 accept", 3306))
+}
+
+func TestReconcile_AdoptLastPrimary(t *testing.T) {
+	c := _newFakeController()
+	c.adoptRunningMariaDB = true
+	c.reconcileTimeout = time.Second
+	c.journalFilePath = filepath.Join(t.TempDir(), "journal")
+	assert.NoError(t, c.recordJournal(StateCandidate, StatePrimary, journalReasonDecided))
+	assert.NoError(t, c.acceptDatabaseServiceTraffic())
+	c.systemdConnector.(*systemd.FakeSystemdConnector).ServiceStarted["mariadb"] = true
+
+	fakeBgpServerConnector := c.bgpServerConnector.(*bgpserver.FakeBgpServerConnector)
+	fakeBgpServerConnector.Routes = []bgpserver.Route{
+		{Prefix: netip.MustParsePrefix("10.0.0.2/32"), Community: bgpCommunityReplica},
+	}
+
+	c.reconcile(context.Background())
+	assert.Equal(t, StatePrimary, c.GetState())
+}
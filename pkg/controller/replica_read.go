@@ -0,0 +1,146 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/nftables"
+)
+
+// ParseReplicaReadSources parses the comma-separated sources like "192.0.2.10,198.51.100.0/24".
+// the empty string is parsed into no source, that allows any source.
+func ParseReplicaReadSources(s string) ([]string, error) {
+	sources := make([]string, 0)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		if prefix, err := netip.ParsePrefix(part); err == nil {
+			sources = append(sources, prefix.Masked().String())
+			continue
+		}
+		addr, err := netip.ParseAddr(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid replica read source %s: %w", part, err)
+		}
+		sources = append(sources, addr.String())
+	}
+
+	return sources, nil
+}
+
+// replicaReadPortOrDefault returns the port that accepts the read traffic on the replica.
+func (c *Controller) replicaReadPortOrDefault() uint16 {
+	if c.replicaReadPort != 0 {
+		return c.replicaReadPort
+	}
+	return c.dbServingPort
+}
+
+// acceptReplicaReadTraffic sets the rules that accept the read traffic on the replica.
+// the read-only clients connect to the read port (the serving port by default) from the sources (any by default),
+// and the other traffic is rejected as in the other states.
+// the trailing reject rules distinguish the replica from the primary that accepts everything.
+func (c *Controller) acceptReplicaReadTraffic() error {
+	if err := c.nftablesConnector.FlushChain(c.dbAclChainName); err != nil {
+		return err
+	}
+
+	readPort := c.replicaReadPortOrDefault()
+	sources := c.replicaReadSources
+	if len(sources) == 0 {
+		// the empty source means any source.
+		sources = []string{""}
+	}
+	for _, src := range sources {
+		acceptMatches := []nftables.Match{nftables.IFNameMatch(c.globalInterfaceName)}
+		if src != "" {
+			acceptMatches = append(acceptMatches, nftables.IPSrcAddrMatch(src))
+		}
+		acceptMatches = append(acceptMatches, nftables.TCPDstPortMatch(readPort))
+		if err := c.nftablesConnector.AddRule(c.dbAclChainName, acceptMatches, nftables.AcceptStatement()); err != nil {
+			return err
+		}
+	}
+
+	rejectPorts := []uint16{c.dbServingPort}
+	if readPort != c.dbServingPort {
+		rejectPorts = append(rejectPorts, readPort)
+	}
+	for _, port := range rejectPorts {
+		rejectMatches := []nftables.Match{
+			nftables.IFNameMatch(c.globalInterfaceName),
+			nftables.TCPDstPortMatch(port),
+		}
+		if err := c.nftablesConnector.AddRule(c.dbAclChainName, rejectMatches, nftables.RejectStatement()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setReplicaDatabaseServiceTraffic sets the rules of the database traffic in replica state.
+func (c *Controller) setReplicaDatabaseServiceTraffic() error {
+	if c.replicaReadEnabled {
+		return c.acceptReplicaReadTraffic()
+	}
+
+	return c.rejectDatabaseServiceTraffic()
+}
+
+// replicaReadLag returns the replication lag that the read-only clients observe.
+// the heartbeat lag is preferred because that includes the delay of the IO thread.
+func (c *Controller) replicaReadLag() (time.Duration, bool) {
+	if lag, ok := c.HeartbeatLag(); ok {
+		return lag, true
+	}
+
+	return c.lastReplicationLag, c.lastReplicationLagKnown
+}
+
+// updateReplicaReadable judges whether the replica serves the read traffic.
+// the replica is readable when the replication is running and the lag is under the limit.
+func (c *Controller) updateReplicaReadable(replicationRunning bool) {
+	readable := c.replicaReadEnabled && replicationRunning
+	if readable && c.replicaReadMaxLag > 0 {
+		lag, known := c.replicaReadLag()
+		readable = known && lag <= c.replicaReadMaxLag
+	}
+
+	c.m.Lock()
+	c.replicaReadable = readable
+	c.m.Unlock()
+
+	if readable {
+		dbControllerReplicaReadableGauge.Set(1)
+	} else {
+		dbControllerReplicaReadableGauge.Set(0)
+	}
+}
+
+// ReplicaReadable returns true if this controller is the replica that serves the read traffic.
+func (c *Controller) ReplicaReadable() bool {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	return c.currentState == StateReplica && c.replicaReadable
+}
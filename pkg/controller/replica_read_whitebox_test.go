@@ -0,0 +1,102 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/nftables"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReplicaReadSources(t *testing.T) {
+	sources, err := ParseReplicaReadSources("192.0.2.10, 198.51.100.1/24")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"192.0.2.10", "198.51.100.0/24"}, sources)
+
+	sources, err = ParseReplicaReadSources("")
+	assert.NoError(t, err)
+	assert.Empty(t, sources)
+
+	_, err = ParseReplicaReadSources("reporting-host")
+	assert.Error(t, err)
+}
+
+func TestAcceptReplicaReadTraffic(t *testing.T) {
+	c := _newFakeController()
+	WithReplicaReadTraffic(3307, []string{"192.0.2.10", "198.51.100.0/24"}, 0)(c)
+
+	assert.NoError(t, c.setReplicaDatabaseServiceTraffic())
+	rules := c.nftablesConnector.(*nftables.FakeNftablesConnector).Rules["dummy-chain-name"]
+	assert.Equal(t, []string{
+		"iifname dummy-global-interface-name ip saddr 192.0.2.10 tcp dport 3307 accept",
+		"iifname dummy-global-interface-name ip saddr 198.51.100.0/24 tcp dport 3307 accept",
+		"iifname dummy-global-interface-name tcp dport 3306 reject",
+		"iifname dummy-global-interface-name tcp dport 3307 reject",
+	}, rules)
+
+	// the replica isn't regarded as the primary on startup.
+	accepted, err := c.isDatabaseServiceTrafficAccepted()
+	assert.NoError(t, err)
+	assert.False(t, accepted)
+}
+
+func TestAdopt_ReplicaServingReadTraffic(t *testing.T) {
+	c := _newFakeController()
+	WithReplicaReadTraffic(0, nil, 0)(c)
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+	assert.NoError(t, c.setReplicaDatabaseServiceTraffic())
+
+	fakeMariaDBConn := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConn.ReadOnlyVariable = true
+	assert.NoError(t, fakeMariaDBConn.ChangeMasterTo(mariadb.MasterInstance{Host: "10.0.0.2"}))
+
+	assert.NoError(t, c.adopt(StateReplica, false))
+	assert.Equal(t, StateReplica, c.GetState())
+}
+
+func TestReplicaReadable(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateReplica)
+
+	// the mode is disabled.
+	c.updateReplicaReadable(true)
+	assert.False(t, c.ReplicaReadable())
+
+	WithReplicaReadTraffic(0, nil, 10*time.Second)(c)
+	c.setHeartbeatLag(3*time.Second, true)
+	c.updateReplicaReadable(true)
+	assert.True(t, c.ReplicaReadable())
+
+	// the replication is broken.
+	c.updateReplicaReadable(false)
+	assert.False(t, c.ReplicaReadable())
+
+	c.setHeartbeatLag(30*time.Second, true)
+	c.updateReplicaReadable(true)
+	assert.False(t, c.ReplicaReadable())
+
+	// Seconds_Behind_Master is used until the heartbeat is measured.
+	c.resetHeartbeatLag()
+	c.observeReplicationLag(mariadb.ReplicationStatus{mariadb.ReplicationStatusSecondsBehindMaster: "1"})
+	c.updateReplicaReadable(true)
+	assert.True(t, c.ReplicaReadable())
+
+	// only the replica serves the read traffic.
+	c.setState(StateCandidate)
+	assert.False(t, c.ReplicaReadable())
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 
 	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/notify"
 )
 
 const (
@@ -35,6 +36,9 @@ func (c *Controller) decideNextStateOnReplica() State {
 	noPrimary := !c.currentNeighbors.primaryNodeExists()
 	noCandidate := !c.currentNeighbors.candidateNodeExists()
 	if noPrimary && noCandidate {
+		if c.yieldsToHigherPriorityNeighbor(StateReplica) {
+			return StateReplica
+		}
 		// you may be the next primary node!
 		return StateCandidate
 	}
@@ -55,6 +59,9 @@ func (c *Controller) triggerRunOnStateChangesToReplica() error {
 	if !c.currentNeighbors.primaryNodeExists() {
 		return fmt.Errorf("there is no primary neighbor in replica mode")
 	}
+	if len(c.currentNeighbors[StatePrimary]) > 1 {
+		return fmt.Errorf("the primary neighbor is not unique in replica mode")
+	}
 
 	if err := c.syncReadOnlyVariable( /* read_only=1 */ true); err != nil {
 		return err
@@ -66,24 +73,12 @@ func (c *Controller) triggerRunOnStateChangesToReplica() error {
 		return err
 	}
 
-	primaryNode := c.currentNeighbors[StatePrimary][0]
-	master := mariadb.MasterInstance{
-		Host:     string(primaryNode),
-		Port:     c.dbReplicaSourcePort,
-		User:     c.dbReplicaUserName,
-		Password: c.dbReplicaPassword,
-		UseGTID:  mariadb.MasterUseGTIDValueCurrentPos,
-	}
-	if err := c.mariaDBConnector.ChangeMasterTo(master); err != nil {
-		return err
-	}
-
-	if err := c.mariaDBConnector.StartReplica(); err != nil {
+	if err := c.startReplicationFrom(c.currentNeighbors[StatePrimary][0]); err != nil {
 		return err
 	}
 
 	// [STEP2]: setting Nftables State.
-	if err := c.rejectDatabaseServiceTraffic(); err != nil {
+	if err := c.setReplicaDatabaseServiceTraffic(); err != nil {
 		return err
 	}
 
@@ -94,6 +89,11 @@ func (c *Controller) triggerRunOnStateChangesToReplica() error {
 
 	// reset the count because the controller is healthy for replica mode.
 	c.replicationStatusCheckFailCount = 0
+	// the lag of the previous replication is meaningless.
+	c.lastReplicationLagKnown = false
+	c.resetHeartbeatLag()
+	// the replica is readable after the replication is confirmed.
+	c.updateReplicaReadable(false)
 
 	c.logger.Info("replica state handler succeed")
 	return nil
@@ -102,9 +102,20 @@ func (c *Controller) triggerRunOnStateChangesToReplica() error {
 func (c *Controller) triggerRunOnStateKeepsReplica() error {
 	if c.replicationStatusCheckFailCount >= replicationStatusCheckThreshold {
 		// we should manually operate the case for recovering.
+		c.notify(notify.Event{
+			Type:    notify.EventReplicationThresholdExhausted,
+			Message: fmt.Sprintf("the replication from %s failed %d times", c.replicationSource, c.replicationStatusCheckFailCount),
+		})
 		return fmt.Errorf("reached the maximum retry limit for replication")
 	}
 
+	// the source is switched before the status check,
+	// because the replication from the replaced primary never satisfies the conditions.
+	if !c.followNewPrimary() {
+		c.updateReplicaReadable(false)
+		return nil
+	}
+
 	if err := c.checkMariaDBReplicationStatus(); err != nil {
 		// we should keep trying to challenge that the replication status satisfies our conditions.
 		c.replicationStatusCheckFailCount++
@@ -113,6 +124,7 @@ func (c *Controller) triggerRunOnStateKeepsReplica() error {
 		if err := c.restartMariaDBReplica(); err != nil {
 			c.logger.Warn("failed to restart replica", "error", err)
 		}
+		c.updateReplicaReadable(false)
 
 		// return noerror because this is soft fail
 		return nil
@@ -121,9 +133,72 @@ func (c *Controller) triggerRunOnStateKeepsReplica() error {
 	// reset the count because the controller is healthy.
 	c.replicationStatusCheckFailCount = 0
 
+	c.observeHeartbeat()
+	c.refreshGTIDAdvertisement()
+	c.updateReplicaReadable(true)
+	return nil
+}
+
+// startReplicationFrom starts the replication from the given primary neighbor.
+func (c *Controller) startReplicationFrom(primaryNode neighbor) error {
+	if err := c.checkDivergenceFrom(primaryNode); err != nil {
+		return err
+	}
+
+	master := mariadb.MasterInstance{
+		Host:     string(primaryNode),
+		Port:     c.dbReplicaSourcePort,
+		User:     c.dbReplicaUserName,
+		Password: c.dbReplicaPassword,
+		UseGTID:  mariadb.MasterUseGTIDValueCurrentPos,
+	}
+	if err := c.mariaDBConnector.ChangeMasterTo(master); err != nil {
+		return err
+	}
+
+	if err := c.mariaDBConnector.StartReplica(); err != nil {
+		return err
+	}
+
+	c.replicationSource = primaryNode
+	c.replicationConfigured = true
 	return nil
 }
 
+// followNewPrimary switches the replication source when another node has been promoted.
+// in the cluster of three or more nodes, the replica may keep its state while the primary is replaced.
+// the function returns false if the switching failed. the failure is counted as the replication failure.
+func (c *Controller) followNewPrimary() bool {
+	primaries := c.currentNeighbors[StatePrimary]
+	if len(primaries) != 1 || primaries[0] == c.replicationSource {
+		// the primaries resolve the dual-primary situation by themselves.
+		return true
+	}
+
+	c.logger.Info("the primary has been replaced. switching the replication source.", "from", c.replicationSource, "to", primaries[0])
+	if err := c.mariaDBConnector.StopReplica(); err != nil {
+		c.replicationStatusCheckFailCount++
+		c.logger.Warn("failed to stop replica", "error", err)
+		return false
+	}
+	if err := c.mariaDBConnector.ResetAllReplicas(); err != nil {
+		c.replicationStatusCheckFailCount++
+		c.logger.Warn("failed to reset replicas", "error", err)
+		return false
+	}
+	if err := c.startReplicationFrom(primaries[0]); err != nil {
+		c.replicationStatusCheckFailCount++
+		c.logger.Warn("failed to switch the replication source", "error", err)
+		return false
+	}
+
+	// the failures of the replication from the replaced primary are no longer relevant.
+	c.replicationStatusCheckFailCount = 0
+	c.lastReplicationLagKnown = false
+	c.resetHeartbeatLag()
+	return true
+}
+
 // checkMariaDBReplicationStatus returns true if the status of replication is satisfied.
 // if the challenge failed to satisfy the conditions, this function returns false.
 func (c *Controller) checkMariaDBReplicationStatus() error {
@@ -131,6 +206,7 @@ func (c *Controller) checkMariaDBReplicationStatus() error {
 	if err != nil {
 		return err
 	}
+	c.observeReplicationLag(status)
 
 	if !c.checkRequiredReplicationStatusIsOK(status) {
 		return fmt.Errorf("failed to satisfy the replication conditions")
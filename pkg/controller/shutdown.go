@@ -0,0 +1,119 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"time"
+)
+
+const (
+	// defaultShutdownTimeout is the default time limit of the handoff on shutdown.
+	defaultShutdownTimeout = 30 * time.Second
+	// shutdownNewPrimaryCheckInterval is the interval of checking the new primary is advertised after the handoff.
+	shutdownNewPrimaryCheckInterval = 500 * time.Millisecond
+)
+
+// ShutdownPolicy specifies how the primary controller stops on the stop signal.
+type ShutdownPolicy string
+
+const (
+	// ShutdownPolicyFault transitions to fault state immediately, that kills MariaDB.
+	ShutdownPolicyFault ShutdownPolicy = "fault"
+	// ShutdownPolicyHandoff hands over the primary role to the replica before transitioning to fault state.
+	ShutdownPolicyHandoff ShutdownPolicy = "handoff"
+	// ShutdownPolicyDetach leaves MariaDB running, so the restarted controller adopts it.
+	// the primary stops accepting writes before exiting, and the restarted controller resumes them.
+	ShutdownPolicyDetach ShutdownPolicy = "detach"
+)
+
+// shutdown stops the controller on the stop signal.
+// the controller ends in fault state, but the primary hands over the role first if the policy allows.
+// with the detach policy, the controller stops without stopping MariaDB.
+func (c *Controller) shutdown() {
+	if c.shutdownPolicy == ShutdownPolicyDetach {
+		if err := c.detach(); err != nil {
+			c.logger.Warn("failed to detach from MariaDB. transition to fault state.", "error", err)
+			c.forceTransitionToFault()
+		}
+		return
+	}
+
+	if c.shutdownPolicy != ShutdownPolicyHandoff || c.GetState() != StatePrimary {
+		c.forceTransitionToFault()
+		return
+	}
+
+	if err := c.handoffOnShutdown(time.Now().Add(c.shutdownTimeout)); err != nil {
+		c.logger.Warn("failed to hand over the primary role on shutdown. transition to fault state.", "error", err)
+		c.forceTransitionToFault()
+		return
+	}
+
+	// the switchover has already advertised fault state but kept MariaDB running.
+	// the demotion hooks have also run in the switchover, so this is the transition from fault state.
+	c.prevState = StateFault
+	if err := c.triggerRunOnStateChanges(); err != nil {
+		c.logger.Info("failed to TriggerRunOnStateChanges while going to fault. Ignore errors.", "error", err)
+	}
+}
+
+// detach stops the controller without stopping MariaDB.
+// the current state is recorded to the journal, so the next controller adopts the running MariaDB in reconcile.
+// the route is withdrawn when the bgp server stops and another node may be promoted during the restart,
+// so the primary turns on read_only and rejects the database traffic to avoid two writers.
+// the restarted controller resumes the writes if this node is still the primary.
+func (c *Controller) detach() error {
+	state := c.GetState()
+	if state == StatePrimary {
+		if err := c.syncReadOnlyVariable( /* read_only=1 */ true); err != nil {
+			return err
+		}
+		if err := c.rejectDatabaseServiceTraffic(); err != nil {
+			return err
+		}
+	}
+
+	if err := c.recordJournal(state, state, journalReasonDetached); err != nil {
+		c.logger.Warn("failed to record the journal on detach. the next start may not adopt MariaDB.", "error", err)
+	}
+
+	c.logger.Info("detached from MariaDB. MariaDB keeps running.", "state", state)
+	return nil
+}
+
+// handoffOnShutdown hands over the primary role to the caught-up replica within the deadline.
+// MariaDB keeps running until the new primary is advertised or the deadline passes,
+// so the replica can be promoted through the usual path.
+func (c *Controller) handoffOnShutdown(deadline time.Time) error {
+	if err := c.switchoverWithReason(journalReasonShutdown, deadline); err != nil {
+		return err
+	}
+
+	for {
+		if err := c.preDecideNextStateHandler(); err != nil {
+			c.logger.Warn("failed to observe the neighbors after the handoff", "error", err)
+		} else if c.currentNeighbors.primaryNodeExists() {
+			c.logger.Info("the new primary is advertised", "primary", c.currentNeighbors[StatePrimary][0])
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			// the replicas have applied everything, so stopping now loses nothing.
+			c.logger.Warn("the new primary isn't advertised in time. stop anyway.")
+			return nil
+		}
+		time.Sleep(shutdownNewPrimaryCheckInterval)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"net/netip"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/bgpserver"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/systemd"
+	"github.com/stretchr/testify/assert"
+)
+
+func _newShutdownController(policy ShutdownPolicy) (*Controller, *mariadb.FakeMariaDBConnector) {
+	c := _newFakeController()
+	WithShutdownPolicy(policy, time.Second)(c)
+	c.setState(StatePrimary)
+	c.currentNeighbors[StateReplica] = []neighbor{"10.0.0.2"}
+
+	fakeMariaDBConn := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConn.GTIDBinlogPos, _ = mariadb.ParseGTIDSet("0-1-100")
+	return c, fakeMariaDBConn
+}
+
+func TestShutdown_Fault(t *testing.T) {
+	c, fakeMariaDBConn := _newShutdownController(ShutdownPolicyFault)
+	fakeMariaDBConn.RemoteGTIDSlavePos["10.0.0.2"], _ = mariadb.ParseGTIDSet("0-1-100")
+
+	c.shutdown()
+	assert.Equal(t, StateFault, c.GetState())
+	assert.False(t, fakeMariaDBConn.ReadOnlyVariable)
+	_, ok := c.systemdConnector.(*systemd.FakeSystemdConnector).Timestamp["KillService"]
+	assert.True(t, ok)
+}
+
+func TestShutdown_Handoff(t *testing.T) {
+	c, fakeMariaDBConn := _newShutdownController(ShutdownPolicyHandoff)
+	fakeMariaDBConn.RemoteGTIDSlavePos["10.0.0.2"], _ = mariadb.ParseGTIDSet("0-1-100")
+	// the replica has been promoted after the handoff.
+	fakeBgpServerConnector := c.bgpServerConnector.(*bgpserver.FakeBgpServerConnector)
+	fakeBgpServerConnector.Routes = []bgpserver.Route{
+		{Prefix: netip.MustParsePrefix("10.0.0.2/32"), Community: bgpCommunityPrimary},
+	}
+
+	c.shutdown()
+	assert.Equal(t, StateFault, c.GetState())
+	assert.True(t, fakeMariaDBConn.ReadOnlyVariable)
+	assert.Equal(t, []neighbor{"10.0.0.2"}, c.currentNeighbors[StatePrimary])
+
+	// MariaDB is stopped after the handoff.
+	_, ok := c.systemdConnector.(*systemd.FakeSystemdConnector).Timestamp["KillService"]
+	assert.True(t, ok)
+}
+
+func TestShutdown_HandoffWithoutReplica(t *testing.T) {
+	c, _ := _newShutdownController(ShutdownPolicyHandoff)
+	c.currentNeighbors[StateReplica] = nil
+
+	c.shutdown()
+	assert.Equal(t, StateFault, c.GetState())
+	_, ok := c.systemdConnector.(*systemd.FakeSystemdConnector).Timestamp["KillService"]
+	assert.True(t, ok)
+}
+
+func TestShutdown_HandoffTimeout(t *testing.T) {
+	c, fakeMariaDBConn := _newShutdownController(ShutdownPolicyHandoff)
+	WithShutdownPolicy(ShutdownPolicyHandoff, time.Millisecond)(c)
+	// the replica doesn't catch up.
+	fakeMariaDBConn.RemoteGTIDSlavePos["10.0.0.2"], _ = mariadb.ParseGTIDSet("0-1-90")
+
+	c.shutdown()
+	assert.Equal(t, StateFault, c.GetState())
+	_, ok := c.systemdConnector.(*systemd.FakeSystemdConnector).Timestamp["KillService"]
+	assert.True(t, ok)
+}
+
+func TestShutdown_DetachThenAdopt(t *testing.T) {
+	c, fakeMariaDBConn := _newShutdownController(ShutdownPolicyDetach)
+	journalFilePath := filepath.Join(t.TempDir(), "journal")
+	WithJournalFilePath(journalFilePath)(c)
+	assert.NoError(t, c.acceptDatabaseServiceTraffic())
+	fakeSystemdConnector := c.systemdConnector.(*systemd.FakeSystemdConnector)
+	fakeSystemdConnector.ServiceStarted["mariadb"] = true
+
+	c.shutdown()
+
+	// MariaDB keeps running, but the writes are stopped while the route is withdrawn.
+	assert.Equal(t, StatePrimary, c.GetState())
+	assert.True(t, fakeMariaDBConn.ReadOnlyVariable)
+	accepted, err := c.isDatabaseServiceTrafficAccepted()
+	assert.NoError(t, err)
+	assert.False(t, accepted)
+	_, ok := fakeSystemdConnector.Timestamp["KillService"]
+	assert.False(t, ok)
+	_, ok = fakeSystemdConnector.Timestamp["StopService"]
+	assert.False(t, ok)
+	last, err := c.lastJournalEntry()
+	assert.NoError(t, err)
+	assert.Equal(t, StatePrimary, last.To)
+	assert.Equal(t, journalReasonDetached, last.Reason)
+
+	// the restarted controller adopts the running MariaDB.
+	restarted := _newFakeController()
+	WithJournalFilePath(journalFilePath)(restarted)
+	WithAdoptRunningMariaDB(true, time.Second)(restarted)
+	restarted.mariaDBConnector = fakeMariaDBConn
+	restarted.systemdConnector = fakeSystemdConnector
+	restarted.nftablesConnector = c.nftablesConnector
+	restarted.bgpServerConnector.(*bgpserver.FakeBgpServerConnector).Routes = []bgpserver.Route{
+		{Prefix: netip.MustParsePrefix("10.0.0.2/32"), Community: bgpCommunityReplica},
+	}
+
+	restarted.reconcile(context.Background())
+	assert.Equal(t, StatePrimary, restarted.GetState())
+	assert.False(t, fakeMariaDBConn.ReadOnlyVariable)
+	accepted, err = restarted.isDatabaseServiceTrafficAccepted()
+	assert.NoError(t, err)
+	assert.True(t, accepted)
+}
+
+func TestShutdown_DetachThenAnotherPrimaryPromoted(t *testing.T) {
+	c, fakeMariaDBConn := _newShutdownController(ShutdownPolicyDetach)
+	journalFilePath := filepath.Join(t.TempDir(), "journal")
+	WithJournalFilePath(journalFilePath)(c)
+	assert.NoError(t, c.acceptDatabaseServiceTraffic())
+	fakeSystemdConnector := c.systemdConnector.(*systemd.FakeSystemdConnector)
+	fakeSystemdConnector.ServiceStarted["mariadb"] = true
+
+	c.shutdown()
+
+	// the replica has been promoted while restarting.
+	restarted := _newFakeController()
+	WithJournalFilePath(journalFilePath)(restarted)
+	WithAdoptRunningMariaDB(true, time.Second)(restarted)
+	restarted.mariaDBConnector = fakeMariaDBConn
+	restarted.systemdConnector = fakeSystemdConnector
+	restarted.nftablesConnector = c.nftablesConnector
+	restarted.bgpServerConnector.(*bgpserver.FakeBgpServerConnector).Routes = []bgpserver.Route{
+		{Prefix: netip.MustParsePrefix("10.0.0.2/32"), Community: bgpCommunityPrimary},
+	}
+
+	restarted.reconcile(context.Background())
+	assert.Equal(t, StateInitial, restarted.GetState())
+	// the old primary never accepts writes.
+	assert.True(t, fakeMariaDBConn.ReadOnlyVariable)
+	accepted, err := restarted.isDatabaseServiceTrafficAccepted()
+	assert.NoError(t, err)
+	assert.False(t, accepted)
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"slices"
+)
+
+// DecideFunc determines the next state that the controller should transition.
+type DecideFunc func(c *Controller) State
+
+// StateHandler is triggered when the controller enters, keeps or exits the state.
+type StateHandler func(c *Controller) error
+
+// Guard is the condition of the transition.
+// the controller keeps the current state if the guard returns false.
+type Guard func(c *Controller) bool
+
+// StateDefinition declares the behavior of a controller state.
+type StateDefinition struct {
+	// Decide determines the next state on the state.
+	Decide DecideFunc
+	// OnEntry is triggered when the controller transitions to the state from the other state.
+	OnEntry StateHandler
+	// OnKeep is triggered when the controller keeps the state.
+	OnKeep StateHandler
+	// OnExit is triggered when the controller transitions from the state to the other state.
+	OnExit StateHandler
+	// Transitions holds the edges from the state.
+	// the key is the next state, and the value is the guard of the edge (nil means no guard).
+	Transitions map[State]Guard
+	// IgnoresNetworkPartition makes the controller call Decide even if the network is parted.
+	// otherwise the controller transitions to fault state on the network partition.
+	IgnoresNetworkPartition bool
+}
+
+// TransitionTable is the declarative definition of the controller state machine.
+type TransitionTable map[State]*StateDefinition
+
+// CanTransition returns true if the table has the edge from current to next.
+func (t TransitionTable) CanTransition(current State, next State) bool {
+	def, ok := t[current]
+	if !ok {
+		return false
+	}
+	if _, ok := t[next]; !ok {
+		return false
+	}
+
+	_, ok = def.Transitions[next]
+	return ok
+}
+
+// States returns the states defined in the table in sorted order.
+func (t TransitionTable) States() []State {
+	states := make([]State, 0, len(t))
+	for s := range t {
+		states = append(states, s)
+	}
+	slices.Sort(states)
+
+	return states
+}
+
+// DefaultTransitionTable returns the state machine of the db-controller.
+// the returned table is newly allocated, so the caller can extend it for custom policies.
+func DefaultTransitionTable() TransitionTable {
+	return TransitionTable{
+		StateInitial: {
+			// just initialized controller take this state.
+			Decide: func(c *Controller) State { return StateFault },
+			Transitions: map[State]Guard{
+				StateFault: nil,
+				// the edges to primary/replica are taken only by the startup reconciliation.
+				StatePrimary: nil,
+				StateReplica: nil,
+			},
+		},
+		StateFault: {
+			Decide:  (*Controller).decideNextStateOnFault,
+			OnEntry: (*Controller).triggerRunOnStateChangesToFault,
+			Transitions: map[State]Guard{
+				StateFault:       nil,
+				StateCandidate:   nil,
+				StateReplica:     nil,
+				StateMaintenance: nil,
+			},
+		},
+		StateCandidate: {
+			Decide:  (*Controller).decideNextStateOnCandidate,
+			OnEntry: (*Controller).triggerRunOnStateChangesToCandidate,
+			OnKeep:  (*Controller).triggerRunOnStateKeepsCandidate,
+			Transitions: map[State]Guard{
+				StateCandidate: nil,
+				StateFault:     nil,
+				StatePrimary:   (*Controller).canBePromotedToPrimary,
+			},
+		},
+		StatePrimary: {
+			Decide:  (*Controller).decideNextStateOnPrimary,
+			OnEntry: (*Controller).triggerRunOnStateChangesToPrimary,
+			OnKeep:  (*Controller).triggerRunOnStateKeepsPrimary,
+			Transitions: map[State]Guard{
+				StatePrimary: nil,
+				StateFault:   nil,
+			},
+		},
+		StateReplica: {
+			Decide:  (*Controller).decideNextStateOnReplica,
+			OnEntry: (*Controller).triggerRunOnStateChangesToReplica,
+			OnKeep:  (*Controller).triggerRunOnStateKeepsReplica,
+			Transitions: map[State]Guard{
+				StateReplica:     nil,
+				StateFault:       nil,
+				StateCandidate:   nil,
+				StateMaintenance: nil,
+			},
+		},
+		StateMaintenance: {
+			Decide:  (*Controller).decideNextStateOnMaintenance,
+			OnEntry: (*Controller).triggerRunOnStateChangesToMaintenance,
+			Transitions: map[State]Guard{
+				StateMaintenance: nil,
+				StateFault:       nil,
+				StateReplica:     nil,
+			},
+			// the maintenance state must be kept even if the network is parted.
+			IgnoresNetworkPartition: true,
+		},
+	}
+}
+
+// canBePromotedToPrimary is the guard for being promoted to primary.
+// the promotion requires the quorum even if the state doesn't ignore the network partition.
+func (c *Controller) canBePromotedToPrimary() bool {
+	return !c.currentNeighbors.primaryNodeExists() && c.hasQuorum()
+}
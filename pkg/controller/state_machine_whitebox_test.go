@@ -0,0 +1,99 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultTransitionTable_Edges(t *testing.T) {
+	table := DefaultTransitionTable()
+
+	cases := []struct {
+		from     State
+		to       State
+		expected bool
+	}{
+		{StateInitial, StateFault, true},
+		{StateInitial, StateCandidate, false},
+		{StateFault, StateCandidate, true},
+		{StateFault, StateReplica, true},
+		{StateFault, StatePrimary, false},
+		{StateCandidate, StatePrimary, true},
+		{StateCandidate, StateReplica, false},
+		{StatePrimary, StateFault, true},
+		{StatePrimary, StateCandidate, false},
+		{StatePrimary, StateReplica, false},
+		{StatePrimary, StateMaintenance, false},
+		{StateReplica, StateCandidate, true},
+		{StateReplica, StatePrimary, false},
+		{StateReplica, StateMaintenance, true},
+		{StateMaintenance, StateReplica, true},
+		{StateMaintenance, StatePrimary, false},
+		{StateAnchor, StateFault, false},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.expected, table.CanTransition(tc.from, tc.to), "%s -> %s", tc.from, tc.to)
+	}
+}
+
+func TestOnStateHandler_UndefinedEdge(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateReplica)
+
+	err := c.onStateHandler(StatePrimary)
+	assert.Error(t, err)
+	assert.Equal(t, StateFault, c.GetState())
+}
+
+func TestOnStateHandler_GuardRejectsTransition(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateCandidate)
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+
+	err := c.onStateHandler(StatePrimary)
+	assert.NoError(t, err)
+	assert.Equal(t, StateCandidate, c.GetState())
+}
+
+func TestTransitionTable_CustomState(t *testing.T) {
+	const stateObserver State = "observer"
+
+	entered := false
+	table := DefaultTransitionTable()
+	table[stateObserver] = &StateDefinition{
+		Decide: func(c *Controller) State { return stateObserver },
+		OnEntry: func(c *Controller) error {
+			entered = true
+			return nil
+		},
+		Transitions: map[State]Guard{stateObserver: nil},
+	}
+	table[StateFault].Transitions[stateObserver] = nil
+
+	c := _newFakeController()
+	WithTransitionTable(table)(c)
+	c.setState(StateFault)
+	c.currentNeighbors[StateFault] = []neighbor{"10.0.0.2"}
+
+	assert.NoError(t, c.onStateHandler(stateObserver))
+	assert.Equal(t, stateObserver, c.GetState())
+	assert.True(t, entered)
+	assert.Equal(t, stateObserver, c.decideNextState())
+	assert.Contains(t, table.States(), stateObserver)
+}
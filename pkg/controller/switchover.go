@@ -0,0 +1,185 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/hook"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+)
+
+const (
+	// defaultSwitchoverTimeout is the default time limit for waiting the replica catches up the primary.
+	defaultSwitchoverTimeout = 30 * time.Second
+	// switchoverCatchUpCheckInterval is the interval of checking the GTID position of the replica.
+	switchoverCatchUpCheckInterval = 500 * time.Millisecond
+)
+
+var (
+	// ErrSwitchoverNotPrimary is returned when the switchover is requested to a non-primary controller.
+	ErrSwitchoverNotPrimary = errors.New("switchover is only allowed in primary state")
+	// ErrSwitchoverNoReplica is returned when there is no replica that takes over the primary role.
+	ErrSwitchoverNoReplica = errors.New("there is no replica to hand over the primary role")
+	// ErrSwitchoverTimeout is returned when the replica doesn't catch up the primary in time.
+	ErrSwitchoverTimeout = errors.New("timed out waiting for the replica to catch up")
+)
+
+// switchoverRequest is a request of the planned switchover from the http-api goroutine.
+type switchoverRequest struct {
+	// result receives the result of the switchover.
+	result chan error
+}
+
+// RequestSwitchover asks the controller loop to hand over the primary role to the replica.
+// the function blocks until the handoff finishes or the given context is done.
+func (c *Controller) RequestSwitchover(ctx context.Context) error {
+	if c.GetState() != StatePrimary {
+		return ErrSwitchoverNotPrimary
+	}
+
+	req := switchoverRequest{result: make(chan error, 1)}
+	select {
+	case c.switchoverRequestCh <- req:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-req.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// switchover hands over the primary role to the replica without losing any transactions.
+// the primary stops accepting writes, waits for the replica catches up, and then advertises fault state.
+// the replica will be promoted through the usual replica->candidate->primary path,
+// and this controller will follow the new primary as a replica.
+// MariaDB keeps running during the handoff so the fault state handler isn't triggered.
+func (c *Controller) switchover() error {
+	return c.switchoverWithReason(journalReasonSwitchover, time.Now().Add(c.switchoverTimeout))
+}
+
+// switchoverWithReason runs the switchover and records the transition with the reason to the journal.
+// the switchover is aborted if the replicas don't catch up by the deadline.
+func (c *Controller) switchoverWithReason(reason string, deadline time.Time) error {
+	if c.GetState() != StatePrimary {
+		return ErrSwitchoverNotPrimary
+	}
+	if !c.currentNeighbors.replicaNodeExists() {
+		return ErrSwitchoverNoReplica
+	}
+
+	c.logger.Info("start switchover", "replicas", c.currentNeighbors[StateReplica])
+	c.runHooksWithoutBlocking(hook.PointPreDemote, StatePrimary, StateFault)
+
+	// [STEP1]: stop accepting writes after the in-flight transactions finish.
+	if err := c.drainConnections(); err != nil {
+		c.logger.Warn("failed to drain the connections. go on the switchover.", "error", err)
+	}
+	if err := c.syncReadOnlyVariable( /* read_only=1 */ true); err != nil {
+		c.undrainConnections()
+		return err
+	}
+
+	// [STEP2]: wait for the replicas to apply all transactions.
+	if err := c.waitForReplicasToCatchUp(deadline); err != nil {
+		c.logger.Warn("switchover is aborted. keep primary state.", "error", err)
+		c.abortSwitchover()
+		return err
+	}
+
+	// [STEP3]: setting nftables state.
+	c.setStateWithReason(StateFault, reason)
+	if err := c.rejectDatabaseServiceTraffic(); err != nil {
+		c.logger.Error("failed to reject database service traffic while switchover. transition to fault state.", "error", err)
+		c.forceTransitionToFault()
+		return err
+	}
+
+	// [STEP4]: configure bgp route.
+	if err := c.advertiseSelfNetIFAddress(); err != nil {
+		c.logger.Error("failed to advertise self-address while switchover. transition to fault state.", "error", err)
+		c.forceTransitionToFault()
+		return err
+	}
+
+	c.runHooksWithoutBlocking(hook.PointPostDemote, StatePrimary, StateFault)
+	c.logger.Info("switchover succeed. waiting for the new primary.")
+	return nil
+}
+
+// abortSwitchover makes the primary accept writes again.
+// the controller goes to fault state if that fails, because the primary can't serve as it is.
+func (c *Controller) abortSwitchover() {
+	if err := c.syncReadOnlyVariable( /* read_only=0 */ false); err != nil {
+		c.logger.Error("failed to turn off read_only while aborting switchover. transition to fault state.", "error", err)
+		c.forceTransitionToFault()
+		return
+	}
+	c.undrainConnections()
+}
+
+// undrainConnections accepts the new connections again that the draining has rejected.
+// the controller goes to fault state if that fails.
+func (c *Controller) undrainConnections() {
+	if c.drainGracePeriod == 0 {
+		return
+	}
+
+	if err := c.acceptDatabaseServiceTraffic(); err != nil {
+		c.logger.Error("failed to accept database service traffic while aborting switchover. transition to fault state.", "error", err)
+		c.forceTransitionToFault()
+	}
+}
+
+// waitForReplicasToCatchUp waits until all replica neighbors apply the transactions of this primary.
+func (c *Controller) waitForReplicasToCatchUp(deadline time.Time) error {
+	target, err := c.mariaDBConnector.ShowGTIDBinlogPos()
+	if err != nil {
+		return err
+	}
+
+	for _, replica := range c.currentNeighbors[StateReplica] {
+		remote := mariadb.RemoteInstance{
+			Host:     string(replica),
+			Port:     c.dbReplicaSourcePort,
+			User:     c.dbReplicaUserName,
+			Password: c.dbReplicaPassword,
+		}
+
+		for {
+			pos, err := c.mariaDBConnector.ShowRemoteGTIDSlavePos(remote)
+			if err != nil {
+				c.logger.Debug("failed to show gtid_slave_pos of the replica", "replica", replica, "error", err)
+			} else if pos.Contains(target) {
+				c.logger.Info("the replica caught up", "replica", replica, "gtid", pos.String())
+				break
+			}
+
+			if time.Now().After(deadline) {
+				return fmt.Errorf("%w: replica %s, target gtid %s", ErrSwitchoverTimeout, replica, target.String())
+			}
+			time.Sleep(switchoverCatchUpCheckInterval)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/bgpserver"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/systemd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSwitchover_OKPath(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StatePrimary)
+	c.currentNeighbors[StateReplica] = []neighbor{"10.0.0.2"}
+
+	fakeMariaDBConn := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConn.GTIDBinlogPos, _ = mariadb.ParseGTIDSet("0-1-100")
+	fakeMariaDBConn.RemoteGTIDSlavePos["10.0.0.2"], _ = mariadb.ParseGTIDSet("0-1-100")
+
+	err := c.switchover()
+	assert.NoError(t, err)
+	assert.Equal(t, StateFault, c.GetState())
+	assert.True(t, fakeMariaDBConn.ReadOnlyVariable)
+
+	// MariaDB must keep running for following the new primary.
+	fakeSystemdConnector := c.systemdConnector.(*systemd.FakeSystemdConnector)
+	_, ok := fakeSystemdConnector.Timestamp["KillService"]
+	assert.False(t, ok)
+	_, ok = fakeSystemdConnector.Timestamp["StopService"]
+	assert.False(t, ok)
+
+	fakeBgpServerConnector := c.bgpServerConnector.(*bgpserver.FakeBgpServerConnector)
+	prefix := netip.PrefixFrom(netip.MustParseAddr("10.0.0.1"), 32)
+	_, ok = fakeBgpServerConnector.RouteConfigured[prefix]
+	assert.True(t, ok)
+}
+
+func TestSwitchover_NotPrimary(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StateReplica)
+	c.currentNeighbors[StatePrimary] = []neighbor{"10.0.0.2"}
+
+	err := c.switchover()
+	assert.ErrorIs(t, err, ErrSwitchoverNotPrimary)
+	assert.Equal(t, StateReplica, c.GetState())
+}
+
+func TestSwitchover_NoReplica(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StatePrimary)
+	c.currentNeighbors[StateFault] = []neighbor{"10.0.0.2"}
+
+	err := c.switchover()
+	assert.ErrorIs(t, err, ErrSwitchoverNoReplica)
+	assert.Equal(t, StatePrimary, c.GetState())
+}
+
+func TestSwitchover_ReplicaDoesNotCatchUp(t *testing.T) {
+	c := _newFakeController()
+	c.setState(StatePrimary)
+	c.currentNeighbors[StateReplica] = []neighbor{"10.0.0.2"}
+	c.switchoverTimeout = time.Millisecond
+
+	fakeMariaDBConn := c.mariaDBConnector.(*mariadb.FakeMariaDBConnector)
+	fakeMariaDBConn.GTIDBinlogPos, _ = mariadb.ParseGTIDSet("0-1-100")
+	fakeMariaDBConn.RemoteGTIDSlavePos["10.0.0.2"], _ = mariadb.ParseGTIDSet("0-1-99")
+
+	err := c.switchover()
+	assert.ErrorIs(t, err, ErrSwitchoverTimeout)
+
+	// the controller must come back to the writable primary.
+	assert.Equal(t, StatePrimary, c.GetState())
+	assert.False(t, fakeMariaDBConn.ReadOnlyVariable)
+}
@@ -0,0 +1,33 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fencing
+
+// FakeFencer is for testing the controller.
+type FakeFencer struct {
+	// Fenced holds the targets that Fence() is called with.
+	Fenced []string
+	// Err is returned by Fence().
+	Err error
+}
+
+func NewFakeFencer() Fencer {
+	return &FakeFencer{}
+}
+
+// Fence implements fencing.Fencer
+func (f *FakeFencer) Fence(target string) error {
+	f.Fenced = append(f.Fenced, target)
+	return f.Err
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fencing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/command"
+)
+
+// Fencer is an interface that isolates the node which may still be running as primary.
+// it is called before the controller is promoted to primary, so the old primary that is
+// cut off from the anchor can't keep accepting writes (STONITH).
+type Fencer interface {
+	// Fence isolates the node of the given address.
+	// the implementation must return nil only if the node is surely unable to serve the database.
+	Fence(target string) error
+}
+
+// execFencer is an implementation of Fencer.
+// this impl executes the script with the target address as the first argument.
+// the script is expected to power off the node via out-of-band management, for example.
+type execFencer struct {
+	logger  *slog.Logger
+	path    string
+	timeout time.Duration
+}
+
+func NewExecFencer(logger *slog.Logger, path string, timeout time.Duration) Fencer {
+	return &execFencer{logger: logger, path: path, timeout: timeout}
+}
+
+// Fence implements Fencer
+func (f *execFencer) Fence(target string) error {
+	args := []string{target}
+	f.logger.Info("execute command", "name", f.path, "args", args)
+	if out, err := command.RunWithTimeout(f.timeout, f.path, args...); err != nil {
+		f.logger.Debug("fencing script", "output", string(out))
+		return fmt.Errorf("failed to fence %s by %s: %w", target, f.path, err)
+	}
+
+	return nil
+}
+
+// httpFencer is an implementation of Fencer.
+// this impl POSTs the target address to the HTTP endpoint such as the cloud API gateway.
+// the fencing is regarded as succeeded when the endpoint responds 2xx status.
+type httpFencer struct {
+	logger  *slog.Logger
+	url     string
+	timeout time.Duration
+	client  *http.Client
+}
+
+// HTTPFenceRequest is the request body that is sent by the http fencer.
+type HTTPFenceRequest struct {
+	Target string `json:"target"`
+}
+
+func NewHTTPFencer(logger *slog.Logger, url string, timeout time.Duration) Fencer {
+	return &httpFencer{logger: logger, url: url, timeout: timeout, client: &http.Client{}}
+}
+
+// Fence implements Fencer
+func (f *httpFencer) Fence(target string) error {
+	body, err := json.Marshal(HTTPFenceRequest{Target: target})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), f.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	f.logger.Info("request fencing", "url", f.url, "target", target)
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fence %s by %s: %w", target, f.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || 300 <= resp.StatusCode {
+		return fmt.Errorf("failed to fence %s by %s: unexpected status %d", target, f.url, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// dryRunFencer is an implementation of Fencer.
+// this impl records the fencing instead of isolating the node, and always succeeds.
+type dryRunFencer struct {
+	recorder *command.Recorder
+}
+
+func NewDryRunFencer(recorder *command.Recorder) Fencer {
+	return &dryRunFencer{recorder: recorder}
+}
+
+// Fence implements Fencer
+func (f *dryRunFencer) Fence(target string) error {
+	f.recorder.Record("fencing", "fence", target)
+	return nil
+}
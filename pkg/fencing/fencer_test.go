@@ -0,0 +1,62 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fencing
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func _newTestLogger() *slog.Logger {
+	return slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{}))
+}
+
+func TestExecFencer(t *testing.T) {
+	f := NewExecFencer(_newTestLogger(), "true", time.Second)
+	assert.NoError(t, f.Fence("10.0.0.2"))
+
+	f = NewExecFencer(_newTestLogger(), "false", time.Second)
+	assert.Error(t, f.Fence("10.0.0.2"))
+}
+
+func TestHTTPFencer(t *testing.T) {
+	var received HTTPFenceRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	f := NewHTTPFencer(_newTestLogger(), srv.URL, time.Second)
+	assert.NoError(t, f.Fence("10.0.0.2"))
+	assert.Equal(t, "10.0.0.2", received.Target)
+}
+
+func TestHTTPFencer_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	f := NewHTTPFencer(_newTestLogger(), srv.URL, time.Second)
+	assert.Error(t, f.Fence("10.0.0.2"))
+}
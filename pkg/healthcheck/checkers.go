@@ -0,0 +1,128 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/systemd"
+)
+
+// systemdUnitChecker is an implementation of HealthChecker.
+// this impl checks the systemd unit is active.
+type systemdUnitChecker struct {
+	connector systemd.Connector
+	unit      string
+}
+
+func NewSystemdUnitChecker(connector systemd.Connector, unit string) HealthChecker {
+	return &systemdUnitChecker{connector: connector, unit: unit}
+}
+
+// Name implements HealthChecker
+func (c *systemdUnitChecker) Name() string {
+	return "systemd"
+}
+
+// Check implements HealthChecker
+func (c *systemdUnitChecker) Check(ctx context.Context) error {
+	return c.connector.CheckServiceStatus(c.unit)
+}
+
+// tcpConnectChecker is an implementation of HealthChecker.
+// this impl checks the database serving port accepts the tcp connection.
+type tcpConnectChecker struct {
+	address string
+}
+
+func NewTCPConnectChecker(address string) HealthChecker {
+	return &tcpConnectChecker{address: address}
+}
+
+// Name implements HealthChecker
+func (c *tcpConnectChecker) Name() string {
+	return "tcp"
+}
+
+// Check implements HealthChecker
+func (c *tcpConnectChecker) Check(ctx context.Context) error {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// queryLatencyChecker is an implementation of HealthChecker.
+// this impl checks "SELECT 1" responds within the maximum latency.
+type queryLatencyChecker struct {
+	connector  mariadb.Connector
+	maxLatency time.Duration
+}
+
+func NewQueryLatencyChecker(connector mariadb.Connector, maxLatency time.Duration) HealthChecker {
+	return &queryLatencyChecker{connector: connector, maxLatency: maxLatency}
+}
+
+// Name implements HealthChecker
+func (c *queryLatencyChecker) Name() string {
+	return "select1"
+}
+
+// Check implements HealthChecker
+func (c *queryLatencyChecker) Check(ctx context.Context) error {
+	start := time.Now()
+	if err := c.connector.Ping(); err != nil {
+		return err
+	}
+
+	if latency := time.Since(start); latency > c.maxLatency {
+		return fmt.Errorf("select 1 took %s (limit %s)", latency, c.maxLatency)
+	}
+	return nil
+}
+
+// innoDBChecker is an implementation of HealthChecker.
+// this impl checks the InnoDB engine is available.
+type innoDBChecker struct {
+	connector mariadb.Connector
+}
+
+func NewInnoDBChecker(connector mariadb.Connector) HealthChecker {
+	return &innoDBChecker{connector: connector}
+}
+
+// Name implements HealthChecker
+func (c *innoDBChecker) Name() string {
+	return "innodb"
+}
+
+// Check implements HealthChecker
+func (c *innoDBChecker) Check(ctx context.Context) error {
+	support, err := c.connector.ShowInnoDBSupport()
+	if err != nil {
+		return err
+	}
+
+	if support != "YES" && support != "DEFAULT" {
+		return fmt.Errorf("InnoDB is not available (support=%q)", support)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HealthChecker is an interface that checks an aspect of the database service.
+type HealthChecker interface {
+	// Name returns the identifier of the check.
+	Name() string
+	// Check returns nil if the aspect is healthy.
+	// the implementation should give up when the given context is done.
+	Check(ctx context.Context) error
+}
+
+// Check is a HealthChecker with its running parameters.
+type Check struct {
+	HealthChecker
+	// Timeout is the time limit of a check.
+	Timeout time.Duration
+	// FailureThreshold is the number of the consecutive failures that makes the check unhealthy.
+	// zero is regarded as 1.
+	FailureThreshold uint
+}
+
+// Result is the latest result of a check.
+type Result struct {
+	Name                string        `json:"name"`
+	Healthy             bool          `json:"healthy"`
+	ConsecutiveFailures uint          `json:"consecutive_failures"`
+	LastError           string        `json:"last_error,omitempty"`
+	Latency             time.Duration `json:"latency_ns"`
+	CheckedAt           time.Time     `json:"checked_at"`
+}
+
+// Runner runs the checks and aggregates their results.
+type Runner struct {
+	checks []Check
+	// results holds the latest result of each check in the order of checks.
+	results []Result
+	// m guards results that are read by the http-api goroutine.
+	m sync.RWMutex
+}
+
+// NewRunner creates the runner of the given checks.
+func NewRunner(checks ...Check) *Runner {
+	results := make([]Result, len(checks))
+	for i, c := range checks {
+		results[i] = Result{Name: c.Name()}
+	}
+
+	return &Runner{checks: checks, results: results}
+}
+
+// Run runs all checks in parallel and returns true if every check is healthy.
+// each failure is counted against the FailureThreshold of the check.
+func (r *Runner) Run(ctx context.Context) bool {
+	return r.run(ctx, true)
+}
+
+// RunUncounted runs all checks like Run, but the failures aren't counted.
+// the failing check keeps its previous verdict, so the out-of-cycle runs
+// (e.g. triggered by the events) don't make the check unhealthy earlier.
+// the success resets the consecutive failures as well as Run.
+func (r *Runner) RunUncounted(ctx context.Context) bool {
+	return r.run(ctx, false)
+}
+
+func (r *Runner) run(ctx context.Context, count bool) bool {
+	errs := make([]error, len(r.checks))
+	latencies := make([]time.Duration, len(r.checks))
+
+	wg := sync.WaitGroup{}
+	for i, c := range r.checks {
+		wg.Add(1)
+		go func(i int, c Check) {
+			defer wg.Done()
+			start := time.Now()
+			errs[i] = runWithTimeout(ctx, c)
+			latencies[i] = time.Since(start)
+		}(i, c)
+	}
+	wg.Wait()
+
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	healthy := true
+	now := time.Now()
+	for i, c := range r.checks {
+		res := &r.results[i]
+		res.CheckedAt = now
+		res.Latency = latencies[i]
+		if errs[i] == nil {
+			res.ConsecutiveFailures = 0
+			res.LastError = ""
+		} else {
+			res.LastError = errs[i].Error()
+			if count {
+				res.ConsecutiveFailures++
+			}
+		}
+
+		threshold := c.FailureThreshold
+		if threshold == 0 {
+			threshold = 1
+		}
+		if errs[i] == nil || count {
+			res.Healthy = res.ConsecutiveFailures < threshold
+		}
+		if !res.Healthy {
+			healthy = false
+		}
+	}
+
+	return healthy
+}
+
+// Results returns the latest result of each check.
+func (r *Runner) Results() []Result {
+	r.m.RLock()
+	defer r.m.RUnlock()
+
+	results := make([]Result, len(r.results))
+	copy(results, r.results)
+	return results
+}
+
+// runWithTimeout runs the check and gives up after the timeout of the check.
+func runWithTimeout(ctx context.Context, c Check) error {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	// the check may not respect the context, so we wait for it in another goroutine.
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", c.Name(), ctx.Err())
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/mariadb"
+	"github.com/stretchr/testify/assert"
+)
+
+// funcChecker is a HealthChecker for testing.
+type funcChecker struct {
+	name string
+	f    func(ctx context.Context) error
+}
+
+func (c *funcChecker) Name() string                    { return c.name }
+func (c *funcChecker) Check(ctx context.Context) error { return c.f(ctx) }
+
+func TestRunner_FailureThreshold(t *testing.T) {
+	var err error
+	r := NewRunner(Check{
+		HealthChecker:    &funcChecker{name: "flaky", f: func(ctx context.Context) error { return err }},
+		FailureThreshold: 2,
+	})
+
+	assert.True(t, r.Run(context.Background()))
+
+	err = errors.New("failed")
+	assert.True(t, r.Run(context.Background()), "the first failure is tolerated")
+	assert.False(t, r.Run(context.Background()))
+
+	results := r.Results()
+	assert.Len(t, results, 1)
+	assert.Equal(t, "flaky", results[0].Name)
+	assert.False(t, results[0].Healthy)
+	assert.Equal(t, uint(2), results[0].ConsecutiveFailures)
+	assert.Equal(t, "failed", results[0].LastError)
+
+	err = nil
+	assert.True(t, r.Run(context.Background()))
+	assert.Equal(t, uint(0), r.Results()[0].ConsecutiveFailures)
+}
+
+func TestRunner_RunUncounted(t *testing.T) {
+	var err error
+	r := NewRunner(Check{
+		HealthChecker: &funcChecker{name: "flaky", f: func(ctx context.Context) error { return err }},
+	})
+
+	assert.True(t, r.Run(context.Background()))
+
+	// the failure is reported, but the verdict is made by the counted run.
+	err = errors.New("failed")
+	assert.True(t, r.RunUncounted(context.Background()))
+	assert.Equal(t, uint(0), r.Results()[0].ConsecutiveFailures)
+	assert.Equal(t, "failed", r.Results()[0].LastError)
+
+	assert.False(t, r.Run(context.Background()))
+	assert.False(t, r.RunUncounted(context.Background()))
+	assert.Equal(t, uint(1), r.Results()[0].ConsecutiveFailures)
+
+	err = nil
+	assert.True(t, r.RunUncounted(context.Background()))
+	assert.Equal(t, uint(0), r.Results()[0].ConsecutiveFailures)
+}
+
+func TestRunner_Timeout(t *testing.T) {
+	r := NewRunner(Check{
+		HealthChecker: &funcChecker{name: "slow", f: func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}},
+		Timeout: 10 * time.Millisecond,
+	})
+
+	assert.False(t, r.Run(context.Background()))
+	assert.Contains(t, r.Results()[0].LastError, "deadline exceeded")
+}
+
+func TestTCPConnectChecker(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	c := NewTCPConnectChecker(l.Addr().String())
+	assert.NoError(t, c.Check(context.Background()))
+
+	assert.NoError(t, l.Close())
+	assert.Error(t, c.Check(context.Background()))
+}
+
+func TestInnoDBChecker(t *testing.T) {
+	conn := mariadb.NewFakeMariaDBConnector().(*mariadb.FakeMariaDBConnector)
+	c := NewInnoDBChecker(conn)
+	assert.NoError(t, c.Check(context.Background()))
+
+	conn.InnoDBSupport = "DISABLED"
+	assert.Error(t, c.Check(context.Background()))
+}
+
+func TestQueryLatencyChecker(t *testing.T) {
+	conn := mariadb.NewFakeMariaDBConnector().(*mariadb.FakeMariaDBConnector)
+	c := NewQueryLatencyChecker(conn, time.Second)
+	assert.NoError(t, c.Check(context.Background()))
+
+	conn.PingErr = errors.New("connection refused")
+	assert.Error(t, c.Check(context.Background()))
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/unix"
+)
+
+var (
+	// storageFreeBytesGaugeVec holds the free bytes of the filesystem that holds the path.
+	storageFreeBytesGaugeVec = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "edb_db_controller_storage_free_bytes",
+			Help: "the free bytes of the filesystem watched by db-controller",
+		},
+		[]string{"path"},
+	)
+	// storageFreeInodesGaugeVec holds the free inodes of the filesystem that holds the path.
+	storageFreeInodesGaugeVec = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "edb_db_controller_storage_free_inodes",
+			Help: "the free inodes of the filesystem watched by db-controller",
+		},
+		[]string{"path"},
+	)
+	// storageReadOnlyGaugeVec is 1 if the filesystem that holds the path is mounted read-only.
+	storageReadOnlyGaugeVec = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "edb_db_controller_storage_readonly",
+			Help: "1 if the filesystem watched by db-controller is mounted read-only",
+		},
+		[]string{"path"},
+	)
+)
+
+// StorageCollectors returns the prometheus metrics of the storage watchdog.
+func StorageCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		storageFreeBytesGaugeVec,
+		storageFreeInodesGaugeVec,
+		storageReadOnlyGaugeVec,
+	}
+}
+
+// storageChecker is an implementation of HealthChecker.
+// this impl watches the filesystems that hold the datadir and the binlog/relay-log directories.
+// the check fails when the free space or the free inodes are about to run out,
+// or the filesystem is remounted read-only, so the primary is demoted before MariaDB hangs.
+type storageChecker struct {
+	paths         []string
+	minFreeBytes  uint64
+	minFreeInodes uint64
+	// statfs is replaceable for testing.
+	statfs func(path string, buf *unix.Statfs_t) error
+}
+
+// NewStorageChecker creates the storage watchdog of the given paths.
+// the duplicated paths are watched once.
+func NewStorageChecker(paths []string, minFreeBytes uint64, minFreeInodes uint64) HealthChecker {
+	watched := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if p != "" && !slices.Contains(watched, p) {
+			watched = append(watched, p)
+		}
+	}
+
+	return &storageChecker{
+		paths:         watched,
+		minFreeBytes:  minFreeBytes,
+		minFreeInodes: minFreeInodes,
+		statfs:        unix.Statfs,
+	}
+}
+
+// Name implements HealthChecker
+func (c *storageChecker) Name() string {
+	return "storage"
+}
+
+// Check implements HealthChecker
+func (c *storageChecker) Check(ctx context.Context) error {
+	var errs []error
+	for _, path := range c.paths {
+		if err := c.checkPath(path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *storageChecker) checkPath(path string) error {
+	var stat unix.Statfs_t
+	if err := c.statfs(path, &stat); err != nil {
+		return fmt.Errorf("failed to statfs %s: %w", path, err)
+	}
+
+	// the blocks are counted in the fragment size. Bsize is the preferred I/O size that may differ.
+	freeBytes := stat.Bavail * uint64(stat.Frsize)
+	readOnly := stat.Flags&unix.ST_RDONLY != 0
+	storageFreeBytesGaugeVec.WithLabelValues(path).Set(float64(freeBytes))
+	storageFreeInodesGaugeVec.WithLabelValues(path).Set(float64(stat.Ffree))
+	if readOnly {
+		storageReadOnlyGaugeVec.WithLabelValues(path).Set(1)
+	} else {
+		storageReadOnlyGaugeVec.WithLabelValues(path).Set(0)
+	}
+
+	if readOnly {
+		return fmt.Errorf("the filesystem of %s is mounted read-only", path)
+	}
+	if freeBytes < c.minFreeBytes {
+		return fmt.Errorf("free space of %s is %d bytes (minimum %d bytes)", path, freeBytes, c.minFreeBytes)
+	}
+	// some filesystems (e.g. btrfs) don't have the fixed number of inodes.
+	if stat.Files != 0 && stat.Ffree < c.minFreeInodes {
+		return fmt.Errorf("free inodes of %s are %d (minimum %d)", path, stat.Ffree, c.minFreeInodes)
+	}
+	return nil
+}
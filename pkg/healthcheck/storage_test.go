@@ -0,0 +1,61 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+func _newTestStorageChecker(stat unix.Statfs_t) *storageChecker {
+	c := NewStorageChecker([]string{"/var/lib/mysql", "/var/lib/mysql", "/var/log/mysql"}, 4096, 10).(*storageChecker)
+	c.statfs = func(path string, buf *unix.Statfs_t) error {
+		*buf = stat
+		return nil
+	}
+	return c
+}
+
+func TestStorageChecker(t *testing.T) {
+	c := _newTestStorageChecker(unix.Statfs_t{Bavail: 8, Frsize: 1024, Files: 100, Ffree: 50})
+	assert.Equal(t, []string{"/var/lib/mysql", "/var/log/mysql"}, c.paths)
+	assert.NoError(t, c.Check(context.Background()))
+}
+
+func TestStorageChecker_LowFreeSpace(t *testing.T) {
+	c := _newTestStorageChecker(unix.Statfs_t{Bavail: 2, Frsize: 1024, Files: 100, Ffree: 50})
+	assert.ErrorContains(t, c.Check(context.Background()), "free space")
+
+	// the blocks are counted in the fragment size, not in the preferred I/O size.
+	c = _newTestStorageChecker(unix.Statfs_t{Bavail: 2, Frsize: 1024, Bsize: 1 << 20, Files: 100, Ffree: 50})
+	assert.ErrorContains(t, c.Check(context.Background()), "free space")
+}
+
+func TestStorageChecker_InodeExhaustion(t *testing.T) {
+	c := _newTestStorageChecker(unix.Statfs_t{Bavail: 8, Frsize: 1024, Files: 100, Ffree: 5})
+	assert.ErrorContains(t, c.Check(context.Background()), "free inodes")
+
+	// the filesystem without the fixed number of inodes.
+	c = _newTestStorageChecker(unix.Statfs_t{Bavail: 8, Frsize: 1024})
+	assert.NoError(t, c.Check(context.Background()))
+}
+
+func TestStorageChecker_ReadOnly(t *testing.T) {
+	c := _newTestStorageChecker(unix.Statfs_t{Bavail: 8, Frsize: 1024, Files: 100, Ffree: 50, Flags: unix.ST_RDONLY})
+	assert.ErrorContains(t, c.Check(context.Background()), "read-only")
+}
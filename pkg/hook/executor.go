@@ -0,0 +1,66 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hook
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/command"
+)
+
+// Executor is an interface that executes a hook.
+type Executor interface {
+	// Execute runs the hook with the environment variables within the timeout of the hook.
+	Execute(h Hook, env []string) error
+}
+
+// execExecutor is an implementation of Executor.
+// this impl runs the executable of the hook.
+type execExecutor struct {
+	logger *slog.Logger
+}
+
+func NewExecExecutor(logger *slog.Logger) Executor {
+	return &execExecutor{logger: logger}
+}
+
+// Execute implements Executor
+func (e *execExecutor) Execute(h Hook, env []string) error {
+	out, err := command.RunWithTimeoutAndEnv(h.Timeout, env, h.Path)
+	if err != nil {
+		e.logger.Debug("hook", "path", h.Path, "output", string(out))
+		return fmt.Errorf("failed to run %s: %w", h.Path, err)
+	}
+
+	return nil
+}
+
+// dryRunExecutor is an implementation of Executor.
+// this impl records the hook instead of running it, and always succeeds.
+type dryRunExecutor struct {
+	recorder *command.Recorder
+}
+
+func NewDryRunExecutor(recorder *command.Recorder) Executor {
+	return &dryRunExecutor{recorder: recorder}
+}
+
+// Execute implements Executor
+func (e *dryRunExecutor) Execute(h Hook, env []string) error {
+	e.recorder.Record("hook", h.Path, strings.Join(env, " "))
+	return nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hook
+
+// FakeExecutor is for testing the controller.
+type FakeExecutor struct {
+	// Executed holds the hooks that Execute() is called with.
+	Executed []Hook
+	// Envs holds the environment variables that Execute() is called with.
+	Envs [][]string
+	// Errs holds the errors returned by Execute() for each path.
+	Errs map[string]error
+}
+
+func NewFakeExecutor() Executor {
+	return &FakeExecutor{Errs: make(map[string]error)}
+}
+
+// Execute implements hook.Executor
+func (e *FakeExecutor) Execute(h Hook, env []string) error {
+	e.Executed = append(e.Executed, h)
+	e.Envs = append(e.Envs, env)
+	return e.Errs[h.Path]
+}
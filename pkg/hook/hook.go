@@ -0,0 +1,176 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hook
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Point is the point of the state transition that the hooks run at.
+type Point string
+
+const (
+	// PointPrePromote runs before the controller is promoted to primary.
+	PointPrePromote Point = "pre-promote"
+	// PointPostPromote runs after the controller is promoted to primary.
+	PointPostPromote Point = "post-promote"
+	// PointPreDemote runs before the primary controller steps down.
+	PointPreDemote Point = "pre-demote"
+	// PointPostDemote runs after the primary controller stepped down.
+	PointPostDemote Point = "post-demote"
+	// PointEnterFault runs after the controller entered fault state.
+	PointEnterFault Point = "enter-fault"
+	// PointEnterReplica runs after the controller entered replica state.
+	PointEnterReplica Point = "enter-replica"
+)
+
+// points are the valid hook points.
+var points = []Point{PointPrePromote, PointPostPromote, PointPreDemote, PointPostDemote, PointEnterFault, PointEnterReplica}
+
+// Mode specifies how the failure of the hook affects the transition.
+type Mode string
+
+const (
+	// ModeBlocking fails the transition when the hook fails, if the transition can be aborted.
+	ModeBlocking Mode = "blocking"
+	// ModeAdvisory only logs the failure of the hook.
+	ModeAdvisory Mode = "advisory"
+)
+
+// Hook is an executable that runs at the point of the state transition.
+type Hook struct {
+	Point Point
+	Path  string
+	// Timeout is the time limit of the hook.
+	Timeout time.Duration
+	Mode    Mode
+}
+
+// Event describes the state transition to the hooks.
+type Event struct {
+	Point       Point
+	From        string
+	To          string
+	HostAddress string
+	// PrimaryAddress is the address of the primary after the transition. empty if no primary is visible.
+	PrimaryAddress string
+	// PeerAddresses are the addresses of the visible neighbors.
+	PeerAddresses []string
+	// GTID is the gtid_binlog_pos of MariaDB. empty if MariaDB didn't respond.
+	GTID  string
+	Epoch uint64
+}
+
+// Environ returns the environment variables that describe the event.
+func (e Event) Environ() []string {
+	return []string{
+		"DBC_HOOK_POINT=" + string(e.Point),
+		"DBC_OLD_STATE=" + e.From,
+		"DBC_NEW_STATE=" + e.To,
+		"DBC_HOST_ADDRESS=" + e.HostAddress,
+		"DBC_PRIMARY_ADDRESS=" + e.PrimaryAddress,
+		"DBC_PEER_ADDRESSES=" + strings.Join(e.PeerAddresses, ","),
+		"DBC_GTID=" + e.GTID,
+		"DBC_EPOCH=" + strconv.FormatUint(e.Epoch, 10),
+	}
+}
+
+// ParseHooks parses the comma-separated hooks like "pre-promote=/usr/local/bin/flush-cache:blocking:10,post-promote=/usr/local/bin/update-sd".
+// each hook is "<point>=<path>[:<mode>[:<timeout seconds>]]", the mode is advisory and the timeout is defaultTimeout when omitted.
+// the hooks of the same point run in the given order.
+func ParseHooks(s string, defaultTimeout time.Duration) ([]Hook, error) {
+	hooks := make([]Hook, 0)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		pointSpec := strings.SplitN(part, "=", 2)
+		if len(pointSpec) != 2 {
+			return nil, fmt.Errorf("invalid hook: %s", part)
+		}
+		h := Hook{Point: Point(pointSpec[0]), Timeout: defaultTimeout, Mode: ModeAdvisory}
+		if !slices.Contains(points, h.Point) {
+			return nil, fmt.Errorf("invalid hook %s: unknown point %s", part, h.Point)
+		}
+
+		spec := strings.Split(pointSpec[1], ":")
+		if len(spec) > 3 || spec[0] == "" {
+			return nil, fmt.Errorf("invalid hook: %s", part)
+		}
+		h.Path = spec[0]
+		if len(spec) >= 2 {
+			h.Mode = Mode(spec[1])
+			if h.Mode != ModeBlocking && h.Mode != ModeAdvisory {
+				return nil, fmt.Errorf("invalid hook %s: the mode must be one of blocking/advisory", part)
+			}
+		}
+		if len(spec) == 3 {
+			sec, err := strconv.Atoi(spec[2])
+			if err != nil || sec <= 0 {
+				return nil, fmt.Errorf("invalid hook %s: the timeout must be positive seconds", part)
+			}
+			h.Timeout = time.Duration(sec) * time.Second
+		}
+		hooks = append(hooks, h)
+	}
+
+	return hooks, nil
+}
+
+// Runner runs the hooks at each point.
+type Runner struct {
+	logger   *slog.Logger
+	executor Executor
+	hooks    []Hook
+}
+
+// NewRunner creates the runner of the given hooks.
+func NewRunner(logger *slog.Logger, executor Executor, hooks ...Hook) *Runner {
+	return &Runner{logger: logger, executor: executor, hooks: hooks}
+}
+
+// Has returns true if any hook runs at the point.
+func (r *Runner) Has(point Point) bool {
+	return slices.ContainsFunc(r.hooks, func(h Hook) bool { return h.Point == point })
+}
+
+// Run runs the hooks of the event point in order.
+// the function returns the error of the first failed blocking hook, and the following hooks are not run.
+// the failure of the advisory hook is only logged.
+func (r *Runner) Run(event Event) error {
+	env := event.Environ()
+	for _, h := range r.hooks {
+		if h.Point != event.Point {
+			continue
+		}
+
+		r.logger.Info("run hook", "point", h.Point, "path", h.Path, "mode", h.Mode)
+		if err := r.executor.Execute(h, env); err != nil {
+			if h.Mode == ModeBlocking {
+				return fmt.Errorf("the blocking hook %s failed at %s: %w", h.Path, h.Point, err)
+			}
+			r.logger.Warn("the advisory hook failed", "point", h.Point, "path", h.Path, "error", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hook
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func _newTestLogger() *slog.Logger {
+	return slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{}))
+}
+
+func TestParseHooks(t *testing.T) {
+	hooks, err := ParseHooks("pre-promote=/usr/local/bin/flush-cache:blocking:5, post-promote=/usr/local/bin/update-sd", 10*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []Hook{
+		{Point: PointPrePromote, Path: "/usr/local/bin/flush-cache", Timeout: 5 * time.Second, Mode: ModeBlocking},
+		{Point: PointPostPromote, Path: "/usr/local/bin/update-sd", Timeout: 10 * time.Second, Mode: ModeAdvisory},
+	}, hooks)
+
+	hooks, err = ParseHooks("", 10*time.Second)
+	assert.NoError(t, err)
+	assert.Empty(t, hooks)
+}
+
+func TestParseHooks_Invalid(t *testing.T) {
+	for _, s := range []string{
+		"/usr/local/bin/flush-cache",
+		"on-promote=/usr/local/bin/flush-cache",
+		"pre-promote=",
+		"pre-promote=/usr/local/bin/flush-cache:sync",
+		"pre-promote=/usr/local/bin/flush-cache:blocking:0",
+		"pre-promote=/usr/local/bin/flush-cache:blocking:5:x",
+	} {
+		_, err := ParseHooks(s, 10*time.Second)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestRunner_Run(t *testing.T) {
+	executor := NewFakeExecutor().(*FakeExecutor)
+	r := NewRunner(_newTestLogger(), executor,
+		Hook{Point: PointPrePromote, Path: "advisory", Mode: ModeAdvisory},
+		Hook{Point: PointPrePromote, Path: "blocking", Mode: ModeBlocking},
+		Hook{Point: PointPrePromote, Path: "after", Mode: ModeAdvisory},
+		Hook{Point: PointEnterFault, Path: "fault", Mode: ModeAdvisory},
+	)
+	assert.True(t, r.Has(PointPrePromote))
+	assert.False(t, r.Has(PointPostPromote))
+
+	// the failure of the advisory hook is ignored.
+	executor.Errs["advisory"] = errors.New("failed")
+	assert.NoError(t, r.Run(Event{Point: PointPrePromote}))
+	assert.Len(t, executor.Executed, 3)
+
+	// the failed blocking hook stops the following hooks.
+	executor.Executed = nil
+	executor.Errs["blocking"] = errors.New("failed")
+	assert.Error(t, r.Run(Event{Point: PointPrePromote}))
+	assert.Len(t, executor.Executed, 2)
+}
+
+func TestExecExecutor(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "env")
+	script := filepath.Join(t.TempDir(), "hook.sh")
+	assert.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\necho \"$DBC_HOOK_POINT $DBC_OLD_STATE $DBC_NEW_STATE $DBC_PEER_ADDRESSES\" > "+out+"\n"), 0o755))
+
+	e := NewExecExecutor(_newTestLogger())
+	event := Event{Point: PointPostPromote, From: "candidate", To: "primary", PeerAddresses: []string{"10.0.0.2", "10.0.0.3"}}
+	assert.NoError(t, e.Execute(Hook{Point: PointPostPromote, Path: script, Timeout: time.Second}, event.Environ()))
+
+	b, err := os.ReadFile(out)
+	assert.NoError(t, err)
+	assert.Equal(t, "post-promote candidate primary 10.0.0.2,10.0.0.3\n", string(b))
+
+	assert.Error(t, e.Execute(Hook{Path: "false", Timeout: time.Second}, nil))
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mariadb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// binlogFileStartPos is the position of the first event in a binary log file.
+// BINLOG_GTID_POS() at this position returns the gtid state when the file was started.
+const binlogFileStartPos = 4
+
+// RemoteBinlogContainsGTID implements Connector
+// the binary log files are walked from the newest one, and the events are listed
+// only in the file that must contain the gtid if the remote has ever recorded it.
+func (c *mySQLCommandConnector) RemoteBinlogContainsGTID(remote RemoteInstance, gtid GTID) (bool, error) {
+	out, err := c.runRemoteMysqlCommand(remote, "show binary logs")
+	if err != nil {
+		return false, fmt.Errorf("failed to show binary logs on %s: %w", remote.Host, err)
+	}
+	files := parseBinaryLogsOutput(string(out))
+
+	for i := len(files) - 1; i >= 0; i-- {
+		out, err := c.runRemoteMysqlCommand(remote, fmt.Sprintf("select binlog_gtid_pos('%s', %d)", files[i], binlogFileStartPos))
+		if err != nil {
+			return false, fmt.Errorf("failed to show the gtid position of %s on %s: %w", files[i], remote.Host, err)
+		}
+		start, err := ParseGTIDSet(strings.TrimSpace(string(out)))
+		if err != nil {
+			return false, err
+		}
+
+		s, ok := start[gtid.DomainID]
+		if ok && s.SeqNo > gtid.SeqNo {
+			// the gtid is recorded in the older file.
+			continue
+		}
+		if ok && s.SeqNo == gtid.SeqNo {
+			// the gtid is the last one of the previous file.
+			return s == gtid, nil
+		}
+
+		out, err = c.runRemoteMysqlCommand(remote, fmt.Sprintf("show binlog events in '%s'", files[i]))
+		if err != nil {
+			return false, fmt.Errorf("failed to show binlog events in %s on %s: %w", files[i], remote.Host, err)
+		}
+		for _, g := range parseBinlogEventsOutput(string(out)) {
+			if g.DomainID == gtid.DomainID && g.SeqNo == gtid.SeqNo {
+				return g == gtid, nil
+			}
+		}
+
+		return false, nil
+	}
+
+	return false, fmt.Errorf("gtid %s has been purged from the binary logs on %s", gtid.String(), remote.Host)
+}
+
+// parseBinaryLogsOutput parses the output of "show binary logs" into the file names in order.
+func parseBinaryLogsOutput(out string) []string {
+	files := []string{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		files = append(files, fields[0])
+	}
+
+	return files
+}
+
+// parseBinlogEventsOutput parses the output of "show binlog events" into the gtids of the transactions.
+// the Info column of the Gtid event is formatted like "BEGIN GTID 0-1-101" or "GTID 0-1-102 cid=10".
+func parseBinlogEventsOutput(out string) []GTID {
+	gtids := []GTID{}
+	for _, line := range strings.Split(out, "\n") {
+		columns := strings.Split(line, "\t")
+		if len(columns) < 6 || columns[2] != "Gtid" {
+			continue
+		}
+
+		fields := strings.Fields(columns[5])
+		for i := 0; i+1 < len(fields); i++ {
+			if fields[i] != "GTID" {
+				continue
+			}
+			if g, err := ParseGTID(fields[i+1]); err == nil {
+				gtids = append(gtids, g)
+			}
+			break
+		}
+	}
+
+	return gtids
+}
@@ -15,9 +15,12 @@
 package mariadb
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,13 +28,26 @@ import (
 )
 
 const (
-	readOnlyVariableName = "read_only"
+	readOnlyVariableName       = "read_only"
+	gtidBinlogPosVariableName  = "gtid_binlog_pos"
+	gtidSlavePosVariableName   = "gtid_slave_pos"
+	gtidCurrentPosVariableName = "gtid_current_pos"
+)
+
+const (
+	// mysqlErrNoSuchTable is the error number of "Table doesn't exist".
+	mysqlErrNoSuchTable = 1146
+	// mysqlErrBadDB is the error number of "Unknown database".
+	mysqlErrBadDB = 1049
 )
 
 var (
 	mysqlCommandTimeout = 5 * time.Second
 )
 
+// ErrMasterGTIDWaitTimeout is returned when MASTER_GTID_WAIT reaches the timeout.
+var ErrMasterGTIDWaitTimeout = errors.New("timed out waiting for the gtid to be applied")
+
 // Connector is an interface that communicates with mariadb.
 type Connector interface {
 	// about readonly variable mechanism
@@ -46,12 +62,39 @@ type Connector interface {
 	ResetAllReplicas() error
 	ShowReplicationStatus() (ReplicationStatus, error)
 
+	// about gtid
+	ShowGTIDBinlogPos() (GTIDSet, error)
+	// ShowGTIDCurrentPos returns the transactions applied to MariaDB whether they're replicated or not.
+	ShowGTIDCurrentPos() (GTIDSet, error)
+	ShowRemoteGTIDSlavePos(remote RemoteInstance) (GTIDSet, error)
+	ShowRemoteGTIDBinlogPos(remote RemoteInstance) (GTIDSet, error)
+	// RemoteBinlogContainsGTID returns true if the binary logs of the remote MariaDB have recorded the gtid.
+	// an error is returned if the binary log that could contain the gtid has been purged.
+	RemoteBinlogContainsGTID(remote RemoteInstance, gtid GTID) (bool, error)
+	// MasterGTIDWait waits until the replica applies the given position.
+	// ErrMasterGTIDWaitTimeout is returned when the timeout is reached.
+	MasterGTIDWait(pos GTIDSet, timeout time.Duration) error
+
 	// about operation for DB health check
+	Ping() error
+	ShowInnoDBSupport() (string, error)
 	CreateDatabase(dbName string) error
 	CreateIDTable(dbName string, tableName string) error
 	InsertIDRecord(dbName string, tableName string, id int) error
 	DeleteRecords(dbName string, tableName string) error
 
+	// about heartbeat
+	CreateHeartbeatTable(dbName string, tableName string) error
+	// WriteHeartbeat writes the heartbeat row of this server with the given timestamp and epoch.
+	WriteHeartbeat(dbName string, tableName string, timestamp time.Time, epoch uint64) error
+	// ShowHeartbeat returns the latest heartbeat row of the highest epoch. ErrHeartbeatNotFound is returned if there is no row.
+	ShowHeartbeat(dbName string, tableName string) (Heartbeat, error)
+
+	// ShowProcessList returns the sessions except the one of the caller.
+	ShowProcessList() ([]Process, error)
+	// KillConnection kills the session and its running query.
+	KillConnection(id uint64) error
+
 	// remove master info or relay info
 	RemoveMasterInfo() error
 	RemoveRelayInfo() error
@@ -105,6 +148,62 @@ func (c *mySQLCommandConnector) InsertIDRecord(dbName string, tableName string,
 	return nil
 }
 
+// CreateHeartbeatTable implements Connector
+func (c *mySQLCommandConnector) CreateHeartbeatTable(dbName string, tableName string) error {
+	createCmd := fmt.Sprintf("create table if not exists %s.%s(server_id int unsigned primary key, ts bigint not null, epoch bigint unsigned not null)", dbName, tableName)
+	if _, err := c.runMysqlCommand(createCmd); err != nil {
+		return fmt.Errorf("failed to create %s table on %s database: %w", tableName, dbName, err)
+	}
+
+	return nil
+}
+
+// WriteHeartbeat implements Connector
+// the timestamp is stored in nanoseconds since the unix epoch.
+func (c *mySQLCommandConnector) WriteHeartbeat(dbName string, tableName string, timestamp time.Time, epoch uint64) error {
+	replaceCmd := fmt.Sprintf("replace into %s.%s(server_id, ts, epoch) values(@@global.server_id, %d, %d)", dbName, tableName, timestamp.UnixNano(), epoch)
+	if _, err := c.runMysqlCommand(replaceCmd); err != nil {
+		return fmt.Errorf("failed to write heartbeat to %s.%s: %w", dbName, tableName, err)
+	}
+
+	return nil
+}
+
+// ShowHeartbeat implements Connector
+func (c *mySQLCommandConnector) ShowHeartbeat(dbName string, tableName string) (Heartbeat, error) {
+	selectCmd := fmt.Sprintf("select server_id, ts, epoch from %s.%s order by epoch desc, ts desc limit 1", dbName, tableName)
+	out, err := c.runMysqlCommand(selectCmd, "-s", "-N")
+	if isMySQLError(err, mysqlErrNoSuchTable) || isMySQLError(err, mysqlErrBadDB) {
+		// the table is created on the first promotion, so no heartbeat has been written on the fresh or upgraded cluster.
+		return Heartbeat{}, fmt.Errorf("%w: %s.%s doesn't exist", ErrHeartbeatNotFound, dbName, tableName)
+	}
+	if err != nil {
+		return Heartbeat{}, fmt.Errorf("failed to show heartbeat from %s.%s: %w", dbName, tableName, err)
+	}
+
+	return parseHeartbeatOutput(string(out))
+}
+
+// ShowProcessList implements Connector
+func (c *mySQLCommandConnector) ShowProcessList() ([]Process, error) {
+	out, err := c.runMysqlCommand(processListQuery, "-s", "-N")
+	if err != nil {
+		return nil, fmt.Errorf("failed to show processlist: %w", err)
+	}
+
+	return parseProcessListOutput(string(out))
+}
+
+// KillConnection implements Connector
+func (c *mySQLCommandConnector) KillConnection(id uint64) error {
+	killCmd := fmt.Sprintf("kill connection %d", id)
+	if _, err := c.runMysqlCommand(killCmd); err != nil {
+		return fmt.Errorf("failed to kill connection %d: %w", id, err)
+	}
+
+	return nil
+}
+
 // IsReadOnly implements Connector
 func (c *mySQLCommandConnector) IsReadOnly() bool {
 	name := "mysql"
@@ -180,7 +279,8 @@ func (c *mySQLCommandConnector) ChangeMasterTo(
 	changeMasterOpts = append(changeMasterOpts, fmt.Sprintf("master_use_gtid = %s", master.UseGTID))
 
 	cmd := fmt.Sprintf("change master to %s", strings.Join(changeMasterOpts, ", "))
-	if out, err := c.runMysqlCommand(cmd); err != nil {
+	// the statement has the password, so it must not be passed on the command line.
+	if out, err := c.runMysqlCommandFromStdin(cmd); err != nil {
 		c.logger.Debug("changeMasterTo", "output", string(out))
 		return fmt.Errorf("failed to change master to: %w", err)
 	}
@@ -198,13 +298,156 @@ func (c *mySQLCommandConnector) ShowReplicationStatus() (ReplicationStatus, erro
 	return parseShowReplicaStatusOutput(string(out)), nil
 }
 
+// ShowGTIDBinlogPos implements Connector
+func (c *mySQLCommandConnector) ShowGTIDBinlogPos() (GTIDSet, error) {
+	return c.showGTIDVariable(gtidBinlogPosVariableName)
+}
+
+// ShowGTIDCurrentPos implements Connector
+func (c *mySQLCommandConnector) ShowGTIDCurrentPos() (GTIDSet, error) {
+	return c.showGTIDVariable(gtidCurrentPosVariableName)
+}
+
+// showGTIDVariable shows the gtid variable of the local MariaDB.
+func (c *mySQLCommandConnector) showGTIDVariable(variableName string) (GTIDSet, error) {
+	out, err := c.runMysqlCommand(fmt.Sprintf("select @@global.%s", variableName), "-s", "-N")
+	if err != nil {
+		return nil, fmt.Errorf("failed to show %s: %w", variableName, err)
+	}
+
+	return ParseGTIDSet(strings.TrimSpace(string(out)))
+}
+
+// ShowRemoteGTIDSlavePos implements Connector
+func (c *mySQLCommandConnector) ShowRemoteGTIDSlavePos(remote RemoteInstance) (GTIDSet, error) {
+	return c.showRemoteGTIDVariable(remote, gtidSlavePosVariableName)
+}
+
+// ShowRemoteGTIDBinlogPos implements Connector
+func (c *mySQLCommandConnector) ShowRemoteGTIDBinlogPos(remote RemoteInstance) (GTIDSet, error) {
+	return c.showRemoteGTIDVariable(remote, gtidBinlogPosVariableName)
+}
+
+// showRemoteGTIDVariable shows the gtid variable of the remote MariaDB.
+func (c *mySQLCommandConnector) showRemoteGTIDVariable(remote RemoteInstance, variableName string) (GTIDSet, error) {
+	out, err := c.runRemoteMysqlCommand(remote, fmt.Sprintf("select @@global.%s", variableName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to show %s on %s: %w", variableName, remote.Host, err)
+	}
+
+	return ParseGTIDSet(strings.TrimSpace(string(out)))
+}
+
+// Ping implements Connector
+func (c *mySQLCommandConnector) Ping() error {
+	if _, err := c.runMysqlCommand("select 1", "-s", "-N"); err != nil {
+		return fmt.Errorf("failed to select 1: %w", err)
+	}
+
+	return nil
+}
+
+// ShowInnoDBSupport implements Connector
+// the result is the "Support" column of the InnoDB engine (YES/DEFAULT/NO/DISABLED).
+func (c *mySQLCommandConnector) ShowInnoDBSupport() (string, error) {
+	out, err := c.runMysqlCommand("select support from information_schema.engines where engine = 'InnoDB'", "-s", "-N")
+	if err != nil {
+		return "", fmt.Errorf("failed to show the support of InnoDB: %w", err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// MasterGTIDWait implements Connector
+func (c *mySQLCommandConnector) MasterGTIDWait(pos GTIDSet, timeout time.Duration) error {
+	out, err := c.runMysqlCommandWithTimeout(
+		// the mysql command itself must not time out before MASTER_GTID_WAIT.
+		timeout+mysqlCommandTimeout,
+		fmt.Sprintf("select master_gtid_wait('%s', %.3f)", pos.String(), timeout.Seconds()),
+		"-s", "-N",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to wait for gtid %s: %w", pos.String(), err)
+	}
+
+	switch strings.TrimSpace(string(out)) {
+	case "0":
+		return nil
+	case "-1":
+		return fmt.Errorf("%w: gtid %s", ErrMasterGTIDWaitTimeout, pos.String())
+	default:
+		return fmt.Errorf("unexpected result of master_gtid_wait: %s", strings.TrimSpace(string(out)))
+	}
+}
+
 // runMysqlCommand executes specified mysql command with timeout and logging
-func (c *mySQLCommandConnector) runMysqlCommand(mysqlcmd string) ([]byte, error) {
+// the extra options are placed before the "-e" option.
+func (c *mySQLCommandConnector) runMysqlCommand(mysqlcmd string, opts ...string) ([]byte, error) {
+	return c.runMysqlCommandWithTimeout(mysqlCommandTimeout, mysqlcmd, opts...)
+}
+
+// runRemoteMysqlCommand executes specified mysql command on the remote MariaDB.
+// the output is formatted in the silent mode without the column names.
+// the password is passed via the environment variable, so it never appears on the command line or in the log.
+func (c *mySQLCommandConnector) runRemoteMysqlCommand(remote RemoteInstance, mysqlcmd string) ([]byte, error) {
+	name := "mysql"
+	args := mysqlArgs(remoteMysqlOptions(remote), mysqlcmd)
+
+	c.logger.Debug("execute command", "name", name, "args", args)
+	return command.RunWithTimeoutEnvAndStdin(mysqlCommandTimeout, remoteMysqlEnv(remote), "", name, args...)
+}
+
+// runMysqlCommandFromStdin executes specified mysql command that is given via the standard input.
+// that is used for the command that has the credentials, so the command itself isn't logged.
+func (c *mySQLCommandConnector) runMysqlCommandFromStdin(mysqlcmd string) ([]byte, error) {
+	name := "mysql"
+
+	c.logger.Debug("execute command from stdin", "name", name)
+	return command.RunWithTimeoutEnvAndStdin(mysqlCommandTimeout, nil, mysqlcmd, name)
+}
+
+// runMysqlCommandWithTimeout executes specified mysql command with the given timeout.
+func (c *mySQLCommandConnector) runMysqlCommandWithTimeout(timeout time.Duration, mysqlcmd string, opts ...string) ([]byte, error) {
 	name := "mysql"
-	args := []string{"-e", mysqlcmd}
+	args := mysqlArgs(opts, mysqlcmd)
 
 	c.logger.Debug("execute command", "name", name, "args", args)
-	return command.RunWithTimeout(mysqlCommandTimeout, name, args...)
+	return command.RunWithTimeout(timeout, name, args...)
+}
+
+// mysqlArgs returns the arguments of the mysql command that places the options before the "-e" option.
+// the arguments are built in a new slice, so the options of the caller are never modified.
+func mysqlArgs(opts []string, mysqlcmd string) []string {
+	args := make([]string, 0, len(opts)+2)
+	args = append(args, opts...)
+	return append(args, "-e", mysqlcmd)
+}
+
+// remoteMysqlOptions returns the options of the mysql command to connect to the remote MariaDB.
+// the password isn't included, see remoteMysqlEnv.
+func remoteMysqlOptions(remote RemoteInstance) []string {
+	return []string{
+		"-s", "-N",
+		"-h", remote.Host,
+		"-P", strconv.Itoa(int(remote.Port)),
+		"-u", remote.User,
+	}
+}
+
+// remoteMysqlEnv returns the environment variables that pass the password of the remote MariaDB to the mysql command.
+func remoteMysqlEnv(remote RemoteInstance) []string {
+	return []string{fmt.Sprintf("MYSQL_PWD=%s", remote.Password)}
+}
+
+// isMySQLError returns true if the mysql command failed with the given error number.
+// the mysql command prints the error like "ERROR 1146 (42S02) at line 1: Table 'management.heartbeat' doesn't exist".
+func isMySQLError(err error, errno int) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+
+	return strings.HasPrefix(strings.TrimSpace(string(exitErr.Stderr)), fmt.Sprintf("ERROR %d ", errno))
 }
 
 func (c *mySQLCommandConnector) RemoveMasterInfo() error {
@@ -231,6 +474,32 @@ func (c *mySQLCommandConnector) RemoveRelayInfo() error {
 	return os.Remove(RelayInfoFilePath)
 }
 
+// parseHeartbeatOutput parses the output of the "mysql -s -N -e 'select server_id, ts, epoch ...'".
+func parseHeartbeatOutput(out string) (Heartbeat, error) {
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return Heartbeat{}, ErrHeartbeatNotFound
+	}
+	if len(fields) != 3 {
+		return Heartbeat{}, fmt.Errorf("unexpected heartbeat row: %s", strings.TrimSpace(out))
+	}
+
+	serverID, err := strconv.ParseUint(fields[0], 10, 32)
+	if err != nil {
+		return Heartbeat{}, fmt.Errorf("invalid server_id of heartbeat: %w", err)
+	}
+	ts, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return Heartbeat{}, fmt.Errorf("invalid ts of heartbeat: %w", err)
+	}
+	epoch, err := strconv.ParseUint(fields[2], 10, 64)
+	if err != nil {
+		return Heartbeat{}, fmt.Errorf("invalid epoch of heartbeat: %w", err)
+	}
+
+	return Heartbeat{ServerID: uint32(serverID), Timestamp: time.Unix(0, ts), Epoch: epoch}, nil
+}
+
 // parseShowReplicaStatusOutput parses the output of the "mysql -e 'show replica status \G'".
 func parseShowReplicaStatusOutput(out string) ReplicationStatus {
 	m := ReplicationStatus{}
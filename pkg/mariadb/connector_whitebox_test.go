@@ -15,8 +15,11 @@
 package mariadb
 
 import (
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/command"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -42,3 +45,109 @@ func TestParseShowReplicaStatusOutput(t *testing.T) {
 	assert.Equal(t, "Yes", result["Slave_SQL_Running"])
 
 }
+
+func TestParseHeartbeatOutput(t *testing.T) {
+	hb, err := parseHeartbeatOutput("2\t1700000000123456789\t42\n")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), hb.ServerID)
+	assert.Equal(t, time.Unix(0, 1700000000123456789), hb.Timestamp)
+	assert.Equal(t, uint64(42), hb.Epoch)
+
+	_, err = parseHeartbeatOutput("")
+	assert.ErrorIs(t, err, ErrHeartbeatNotFound)
+
+	_, err = parseHeartbeatOutput("2\tabc\t42\n")
+	assert.Error(t, err)
+}
+
+func TestParseProcessListOutput(t *testing.T) {
+	out := "5\tsystem user\t\t\tDaemon\t0\t0\n" +
+		"12\trepl\t10.0.0.2:41234\t\tBinlog Dump GTID\t3600\t0\n" +
+		"20\tapp\t192.0.2.10:50000\tshop\tQuery\t2\t1\n" +
+		"21\tapp\t192.0.2.10:50001\tshop\tSleep\t30\t0\n"
+	processes, err := parseProcessListOutput(out)
+	assert.NoError(t, err)
+	assert.Len(t, processes, 4)
+
+	assert.False(t, processes[0].IsClientSession())
+	assert.False(t, processes[1].IsClientSession())
+
+	assert.True(t, processes[2].IsClientSession())
+	assert.True(t, processes[2].IsBusy())
+	assert.Equal(t, "shop", processes[2].DB)
+	assert.Equal(t, "20(app@192.0.2.10:50000)", processes[2].String())
+
+	assert.True(t, processes[3].IsClientSession())
+	assert.False(t, processes[3].IsBusy())
+	assert.Equal(t, 30*time.Second, processes[3].Time)
+
+	// the idle session in a transaction is busy.
+	processes[3].InTransaction = true
+	assert.True(t, processes[3].IsBusy())
+
+	processes, err = parseProcessListOutput("")
+	assert.NoError(t, err)
+	assert.Empty(t, processes)
+
+	_, err = parseProcessListOutput("20\tapp\n")
+	assert.Error(t, err)
+}
+
+func TestParseBinaryLogsOutput(t *testing.T) {
+	files := parseBinaryLogsOutput("mysql-bin.000001\t1024\nmysql-bin.000002\t356\n")
+	assert.Equal(t, []string{"mysql-bin.000001", "mysql-bin.000002"}, files)
+
+	assert.Empty(t, parseBinaryLogsOutput(""))
+}
+
+func TestParseBinlogEventsOutput(t *testing.T) {
+	const input = "mysql-bin.000002\t4\tFormat_desc\t1\t256\tServer ver: 10.11.6-MariaDB-log, Binlog ver: 4\n" +
+		"mysql-bin.000002\t256\tGtid_list\t1\t299\t[0-1-100]\n" +
+		"mysql-bin.000002\t299\tGtid\t2\t341\tBEGIN GTID 0-2-101\n" +
+		"mysql-bin.000002\t341\tQuery\t2\t450\tinsert into t values(1)\n" +
+		"mysql-bin.000002\t450\tGtid\t2\t492\tGTID 0-2-102 cid=12\n"
+
+	gtids := parseBinlogEventsOutput(input)
+	assert.Equal(t, []GTID{
+		{DomainID: 0, ServerID: 2, SeqNo: 101},
+		{DomainID: 0, ServerID: 2, SeqNo: 102},
+	}, gtids)
+}
+
+func TestIsMySQLError(t *testing.T) {
+	// the error of the real command carries the stderr.
+	_, err := command.RunWithTimeout(time.Second, "sh", "-c", `echo "ERROR 1146 (42S02) at line 1: Table 'management.heartbeat' doesn't exist" >&2; exit 1`)
+	assert.True(t, isMySQLError(err, mysqlErrNoSuchTable))
+	assert.False(t, isMySQLError(err, mysqlErrBadDB))
+
+	assert.False(t, isMySQLError(nil, mysqlErrNoSuchTable))
+	assert.False(t, isMySQLError(errors.New("ERROR 1146"), mysqlErrNoSuchTable))
+}
+
+func TestMysqlArgs(t *testing.T) {
+	opts := make([]string, 2, 8)
+	opts[0], opts[1] = "-s", "-N"
+
+	args := mysqlArgs(opts, "select 1")
+	assert.Equal(t, []string{"-s", "-N", "-e", "select 1"}, args)
+
+	// the spare capacity of the caller's slice must not be shared.
+	other := mysqlArgs(opts, "select 2")
+	assert.Equal(t, []string{"-s", "-N", "-e", "select 1"}, args)
+	assert.Equal(t, []string{"-s", "-N", "-e", "select 2"}, other)
+}
+
+func TestRemoteMysqlOptions_WithoutPassword(t *testing.T) {
+	remote := RemoteInstance{Host: "10.0.0.2", Port: 3306, User: "repl", Password: "secret"}
+
+	for _, opt := range remoteMysqlOptions(remote) {
+		assert.NotContains(t, opt, "secret")
+	}
+	assert.Equal(t, []string{"MYSQL_PWD=secret"}, remoteMysqlEnv(remote))
+}
+
+func TestIsMySQLError_WithEnvAndStdin(t *testing.T) {
+	// the standard error must be kept for isMySQLError.
+	_, err := command.RunWithTimeoutEnvAndStdin(time.Second, []string{"MYSQL_PWD=secret"}, "select 1", "sh", "-c", `cat >/dev/null; echo "ERROR 1049 (42000): Unknown database 'management'" >&2; exit 1`)
+	assert.True(t, isMySQLError(err, mysqlErrBadDB))
+}
@@ -0,0 +1,193 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mariadb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/command"
+)
+
+// dryRunConnector is an implementation of Connector.
+// this impl records the statements that change MariaDB instead of running them.
+// the queries that only read are delegated to the underlying connector.
+type dryRunConnector struct {
+	connector Connector
+	recorder  *command.Recorder
+}
+
+func NewDryRunConnector(connector Connector, recorder *command.Recorder) Connector {
+	return &dryRunConnector{connector: connector, recorder: recorder}
+}
+
+// record records the mysql command.
+func (c *dryRunConnector) record(mysqlcmd string) {
+	c.recorder.Record("mariadb", "mysql", "-e", mysqlcmd)
+}
+
+// IsReadOnly implements Connector
+func (c *dryRunConnector) IsReadOnly() bool {
+	return c.connector.IsReadOnly()
+}
+
+// TurnOnReadOnly implements Connector
+func (c *dryRunConnector) TurnOnReadOnly() error {
+	c.record(fmt.Sprintf("set global %s=1", readOnlyVariableName))
+	return nil
+}
+
+// TurnOffReadOnly implements Connector
+func (c *dryRunConnector) TurnOffReadOnly() error {
+	c.record(fmt.Sprintf("set global %s=0", readOnlyVariableName))
+	return nil
+}
+
+// ChangeMasterTo implements Connector
+func (c *dryRunConnector) ChangeMasterTo(master MasterInstance) error {
+	// the password must not be exposed via the api.
+	c.record(fmt.Sprintf(
+		"change master to master_host = \"%s\", master_port = %d, master_user = \"%s\", master_password = \"********\", master_use_gtid = %s",
+		master.Host, master.Port, master.User, master.UseGTID,
+	))
+	return nil
+}
+
+// StartReplica implements Connector
+func (c *dryRunConnector) StartReplica() error {
+	c.record("start replica")
+	return nil
+}
+
+// StopReplica implements Connector
+func (c *dryRunConnector) StopReplica() error {
+	c.record("stop replica")
+	return nil
+}
+
+// ResetAllReplicas implements Connector
+func (c *dryRunConnector) ResetAllReplicas() error {
+	c.record("reset replica all")
+	return nil
+}
+
+// ShowReplicationStatus implements Connector
+func (c *dryRunConnector) ShowReplicationStatus() (ReplicationStatus, error) {
+	return c.connector.ShowReplicationStatus()
+}
+
+// ShowGTIDBinlogPos implements Connector
+func (c *dryRunConnector) ShowGTIDBinlogPos() (GTIDSet, error) {
+	return c.connector.ShowGTIDBinlogPos()
+}
+
+// ShowGTIDCurrentPos implements Connector
+func (c *dryRunConnector) ShowGTIDCurrentPos() (GTIDSet, error) {
+	return c.connector.ShowGTIDCurrentPos()
+}
+
+// ShowRemoteGTIDSlavePos implements Connector
+func (c *dryRunConnector) ShowRemoteGTIDSlavePos(remote RemoteInstance) (GTIDSet, error) {
+	return c.connector.ShowRemoteGTIDSlavePos(remote)
+}
+
+// ShowRemoteGTIDBinlogPos implements Connector
+func (c *dryRunConnector) ShowRemoteGTIDBinlogPos(remote RemoteInstance) (GTIDSet, error) {
+	return c.connector.ShowRemoteGTIDBinlogPos(remote)
+}
+
+// RemoteBinlogContainsGTID implements Connector
+func (c *dryRunConnector) RemoteBinlogContainsGTID(remote RemoteInstance, gtid GTID) (bool, error) {
+	return c.connector.RemoteBinlogContainsGTID(remote, gtid)
+}
+
+// MasterGTIDWait implements Connector
+func (c *dryRunConnector) MasterGTIDWait(pos GTIDSet, timeout time.Duration) error {
+	return c.connector.MasterGTIDWait(pos, timeout)
+}
+
+// Ping implements Connector
+func (c *dryRunConnector) Ping() error {
+	return c.connector.Ping()
+}
+
+// ShowInnoDBSupport implements Connector
+func (c *dryRunConnector) ShowInnoDBSupport() (string, error) {
+	return c.connector.ShowInnoDBSupport()
+}
+
+// CreateDatabase implements Connector
+func (c *dryRunConnector) CreateDatabase(dbName string) error {
+	c.record(fmt.Sprintf("create database if not exists %s", dbName))
+	return nil
+}
+
+// CreateIDTable implements Connector
+func (c *dryRunConnector) CreateIDTable(dbName string, tableName string) error {
+	c.record(fmt.Sprintf("create table if not exists %s.%s(id int)", dbName, tableName))
+	return nil
+}
+
+// InsertIDRecord implements Connector
+func (c *dryRunConnector) InsertIDRecord(dbName string, tableName string, id int) error {
+	c.record(fmt.Sprintf("insert into %s.%s values(%d)", dbName, tableName, id))
+	return nil
+}
+
+// DeleteRecords implements Connector
+func (c *dryRunConnector) DeleteRecords(dbName string, tableName string) error {
+	c.record(fmt.Sprintf("delete from %s.%s", dbName, tableName))
+	return nil
+}
+
+// CreateHeartbeatTable implements Connector
+func (c *dryRunConnector) CreateHeartbeatTable(dbName string, tableName string) error {
+	c.record(fmt.Sprintf("create table if not exists %s.%s(server_id int unsigned primary key, ts bigint not null, epoch bigint unsigned not null)", dbName, tableName))
+	return nil
+}
+
+// WriteHeartbeat implements Connector
+func (c *dryRunConnector) WriteHeartbeat(dbName string, tableName string, timestamp time.Time, epoch uint64) error {
+	c.record(fmt.Sprintf("replace into %s.%s(server_id, ts, epoch) values(@@global.server_id, %d, %d)", dbName, tableName, timestamp.UnixNano(), epoch))
+	return nil
+}
+
+// ShowHeartbeat implements Connector
+func (c *dryRunConnector) ShowHeartbeat(dbName string, tableName string) (Heartbeat, error) {
+	return c.connector.ShowHeartbeat(dbName, tableName)
+}
+
+// ShowProcessList implements Connector
+func (c *dryRunConnector) ShowProcessList() ([]Process, error) {
+	return c.connector.ShowProcessList()
+}
+
+// KillConnection implements Connector
+func (c *dryRunConnector) KillConnection(id uint64) error {
+	c.record(fmt.Sprintf("kill connection %d", id))
+	return nil
+}
+
+// RemoveMasterInfo implements Connector
+func (c *dryRunConnector) RemoveMasterInfo() error {
+	c.recorder.Record("mariadb", "rm", "-f", MasterInfoFilePath)
+	return nil
+}
+
+// RemoveRelayInfo implements Connector
+func (c *dryRunConnector) RemoveRelayInfo() error {
+	c.recorder.Record("mariadb", "rm", "-f", RelayInfoFilePath)
+	return nil
+}
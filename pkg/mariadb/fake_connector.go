@@ -16,6 +16,7 @@ package mariadb
 
 import (
 	"fmt"
+	"slices"
 	"time"
 )
 
@@ -23,13 +24,48 @@ type FakeMariaDBConnector struct {
 	Timestamp        map[string]time.Time
 	ReadOnlyVariable bool
 	MasterConfig     MasterInstance
+	// GTIDBinlogPos is returned by ShowGTIDBinlogPos().
+	GTIDBinlogPos GTIDSet
+	// GTIDCurrentPos is returned by ShowGTIDCurrentPos().
+	GTIDCurrentPos GTIDSet
+	// RemoteGTIDSlavePos is returned by ShowRemoteGTIDSlavePos() for each remote host.
+	RemoteGTIDSlavePos map[string]GTIDSet
+	// RemoteGTIDBinlogPos is returned by ShowRemoteGTIDBinlogPos() for each remote host.
+	// the empty set is returned for the unknown host.
+	RemoteGTIDBinlogPos map[string]GTIDSet
+	// RemoteGTIDHistory is looked up by RemoteBinlogContainsGTID() for each remote host.
+	// every gtid is regarded as recorded on the host that isn't in the map.
+	RemoteGTIDHistory map[string][]GTID
+	// MasterGTIDWaitErr is returned by MasterGTIDWait().
+	MasterGTIDWaitErr error
+	// PingErr is returned by Ping().
+	PingErr error
+	// InnoDBSupport is returned by ShowInnoDBSupport().
+	InnoDBSupport string
+	// Heartbeat is written by WriteHeartbeat() and returned by ShowHeartbeat().
+	// nil means no heartbeat has been written.
+	Heartbeat *Heartbeat
+	// ShowHeartbeatErr is returned by ShowHeartbeat() if it is not nil.
+	ShowHeartbeatErr error
+	// ReplicationStatusOverride is merged into the result of ShowReplicationStatus().
+	ReplicationStatusOverride ReplicationStatus
+	// Processes is returned by ShowProcessList(). KillConnection() removes the killed one.
+	Processes []Process
+	// Killed holds the ids that KillConnection() is called with.
+	Killed []uint64
 }
 
 func NewFakeMariaDBConnector() Connector {
 	return &FakeMariaDBConnector{
-		Timestamp:        make(map[string]time.Time),
-		ReadOnlyVariable: false,
-		MasterConfig:     MasterInstance{},
+		Timestamp:           make(map[string]time.Time),
+		ReadOnlyVariable:    false,
+		MasterConfig:        MasterInstance{},
+		GTIDBinlogPos:       GTIDSet{},
+		GTIDCurrentPos:      GTIDSet{},
+		RemoteGTIDSlavePos:  make(map[string]GTIDSet),
+		RemoteGTIDBinlogPos: make(map[string]GTIDSet),
+		RemoteGTIDHistory:   make(map[string][]GTID),
+		InnoDBSupport:       "DEFAULT",
 	}
 }
 
@@ -50,12 +86,76 @@ func (c *FakeMariaDBConnector) ResetAllReplicas() error {
 func (c *FakeMariaDBConnector) ShowReplicationStatus() (ReplicationStatus, error) {
 	c.Timestamp["ShowReplicationStatus"] = time.Now()
 	status := ReplicationStatus{
+		ReplicationStatusMasterHost:      c.MasterConfig.Host,
 		ReplicationStatusSlaveIORunning:  "Yes",
 		ReplicationStatusSlaveSQLRunning: "Yes",
 	}
+	for k, v := range c.ReplicationStatusOverride {
+		status[k] = v
+	}
 	return status, nil
 }
 
+// ShowGTIDBinlogPos implements mariadb.Connector
+func (c *FakeMariaDBConnector) ShowGTIDBinlogPos() (GTIDSet, error) {
+	c.Timestamp["ShowGTIDBinlogPos"] = time.Now()
+	return c.GTIDBinlogPos, nil
+}
+
+// ShowGTIDCurrentPos implements mariadb.Connector
+func (c *FakeMariaDBConnector) ShowGTIDCurrentPos() (GTIDSet, error) {
+	c.Timestamp["ShowGTIDCurrentPos"] = time.Now()
+	return c.GTIDCurrentPos, nil
+}
+
+// ShowRemoteGTIDSlavePos implements mariadb.Connector
+func (c *FakeMariaDBConnector) ShowRemoteGTIDSlavePos(remote RemoteInstance) (GTIDSet, error) {
+	c.Timestamp[fmt.Sprintf("ShowRemoteGTIDSlavePos(%s)", remote.Host)] = time.Now()
+	pos, ok := c.RemoteGTIDSlavePos[remote.Host]
+	if !ok {
+		return nil, fmt.Errorf("failed to connect to %s", remote.Host)
+	}
+	return pos, nil
+}
+
+// ShowRemoteGTIDBinlogPos implements mariadb.Connector
+func (c *FakeMariaDBConnector) ShowRemoteGTIDBinlogPos(remote RemoteInstance) (GTIDSet, error) {
+	c.Timestamp[fmt.Sprintf("ShowRemoteGTIDBinlogPos(%s)", remote.Host)] = time.Now()
+	pos, ok := c.RemoteGTIDBinlogPos[remote.Host]
+	if !ok {
+		return GTIDSet{}, nil
+	}
+	return pos, nil
+}
+
+// Ping implements mariadb.Connector
+func (c *FakeMariaDBConnector) Ping() error {
+	c.Timestamp["Ping"] = time.Now()
+	return c.PingErr
+}
+
+// ShowInnoDBSupport implements mariadb.Connector
+func (c *FakeMariaDBConnector) ShowInnoDBSupport() (string, error) {
+	c.Timestamp["ShowInnoDBSupport"] = time.Now()
+	return c.InnoDBSupport, nil
+}
+
+// RemoteBinlogContainsGTID implements mariadb.Connector
+func (c *FakeMariaDBConnector) RemoteBinlogContainsGTID(remote RemoteInstance, gtid GTID) (bool, error) {
+	c.Timestamp[fmt.Sprintf("RemoteBinlogContainsGTID(%s)", remote.Host)] = time.Now()
+	history, ok := c.RemoteGTIDHistory[remote.Host]
+	if !ok {
+		return true, nil
+	}
+	return slices.Contains(history, gtid), nil
+}
+
+// MasterGTIDWait implements mariadb.Connector
+func (c *FakeMariaDBConnector) MasterGTIDWait(pos GTIDSet, timeout time.Duration) error {
+	c.Timestamp["MasterGTIDWait"] = time.Now()
+	return c.MasterGTIDWaitErr
+}
+
 // StartReplica implements mariadb.Connector
 func (c *FakeMariaDBConnector) StartReplica() error {
 	c.Timestamp["StartReplica"] = time.Now()
@@ -106,6 +206,45 @@ func (c *FakeMariaDBConnector) DeleteRecords(dbName string, tableName string) er
 	return nil
 }
 
+// CreateHeartbeatTable implements mariadb.Connector
+func (c *FakeMariaDBConnector) CreateHeartbeatTable(dbName string, tableName string) error {
+	c.Timestamp["CreateHeartbeatTable"] = time.Now()
+	return nil
+}
+
+// WriteHeartbeat implements mariadb.Connector
+func (c *FakeMariaDBConnector) WriteHeartbeat(dbName string, tableName string, timestamp time.Time, epoch uint64) error {
+	c.Timestamp["WriteHeartbeat"] = time.Now()
+	c.Heartbeat = &Heartbeat{ServerID: 1, Timestamp: timestamp, Epoch: epoch}
+	return nil
+}
+
+// ShowHeartbeat implements mariadb.Connector
+func (c *FakeMariaDBConnector) ShowHeartbeat(dbName string, tableName string) (Heartbeat, error) {
+	c.Timestamp["ShowHeartbeat"] = time.Now()
+	if c.ShowHeartbeatErr != nil {
+		return Heartbeat{}, c.ShowHeartbeatErr
+	}
+	if c.Heartbeat == nil {
+		return Heartbeat{}, ErrHeartbeatNotFound
+	}
+	return *c.Heartbeat, nil
+}
+
+// ShowProcessList implements mariadb.Connector
+func (c *FakeMariaDBConnector) ShowProcessList() ([]Process, error) {
+	c.Timestamp["ShowProcessList"] = time.Now()
+	return slices.Clone(c.Processes), nil
+}
+
+// KillConnection implements mariadb.Connector
+func (c *FakeMariaDBConnector) KillConnection(id uint64) error {
+	c.Timestamp["KillConnection"] = time.Now()
+	c.Killed = append(c.Killed, id)
+	c.Processes = slices.DeleteFunc(c.Processes, func(p Process) bool { return p.ID == id })
+	return nil
+}
+
 // InsertIDRecord implements mariadb.Connector
 func (c *FakeMariaDBConnector) InsertIDRecord(dbName string, tableName string, id int) error {
 	c.Timestamp[fmt.Sprintf("InsertIDRecord(%s, %s, %d)", dbName, tableName, id)] = time.Now()
@@ -153,6 +292,46 @@ func (c *FakeMariaDBFailWriteTestDataConnector) ShowReplicationStatus() (Replica
 	return status, nil
 }
 
+// ShowGTIDBinlogPos implements mariadb.Connector
+func (c *FakeMariaDBFailWriteTestDataConnector) ShowGTIDBinlogPos() (GTIDSet, error) {
+	return GTIDSet{}, nil
+}
+
+// ShowGTIDCurrentPos implements mariadb.Connector
+func (c *FakeMariaDBFailWriteTestDataConnector) ShowGTIDCurrentPos() (GTIDSet, error) {
+	return GTIDSet{}, nil
+}
+
+// ShowRemoteGTIDSlavePos implements mariadb.Connector
+func (c *FakeMariaDBFailWriteTestDataConnector) ShowRemoteGTIDSlavePos(remote RemoteInstance) (GTIDSet, error) {
+	return GTIDSet{}, nil
+}
+
+// ShowRemoteGTIDBinlogPos implements mariadb.Connector
+func (c *FakeMariaDBFailWriteTestDataConnector) ShowRemoteGTIDBinlogPos(remote RemoteInstance) (GTIDSet, error) {
+	return GTIDSet{}, nil
+}
+
+// RemoteBinlogContainsGTID implements mariadb.Connector
+func (c *FakeMariaDBFailWriteTestDataConnector) RemoteBinlogContainsGTID(remote RemoteInstance, gtid GTID) (bool, error) {
+	return true, nil
+}
+
+// Ping implements mariadb.Connector
+func (c *FakeMariaDBFailWriteTestDataConnector) Ping() error {
+	return nil
+}
+
+// ShowInnoDBSupport implements mariadb.Connector
+func (c *FakeMariaDBFailWriteTestDataConnector) ShowInnoDBSupport() (string, error) {
+	return "DEFAULT", nil
+}
+
+// MasterGTIDWait implements mariadb.Connector
+func (c *FakeMariaDBFailWriteTestDataConnector) MasterGTIDWait(pos GTIDSet, timeout time.Duration) error {
+	return nil
+}
+
 // StartReplica implements mariadb.Connector
 func (c *FakeMariaDBFailWriteTestDataConnector) StartReplica() error {
 	return nil
@@ -188,6 +367,31 @@ func (*FakeMariaDBFailWriteTestDataConnector) DeleteRecords(dbName string, table
 	return nil
 }
 
+// CreateHeartbeatTable implements mariadb.Connector
+func (*FakeMariaDBFailWriteTestDataConnector) CreateHeartbeatTable(dbName string, tableName string) error {
+	return nil
+}
+
+// WriteHeartbeat implements mariadb.Connector
+func (*FakeMariaDBFailWriteTestDataConnector) WriteHeartbeat(dbName string, tableName string, timestamp time.Time, epoch uint64) error {
+	return nil
+}
+
+// ShowHeartbeat implements mariadb.Connector
+func (*FakeMariaDBFailWriteTestDataConnector) ShowHeartbeat(dbName string, tableName string) (Heartbeat, error) {
+	return Heartbeat{}, ErrHeartbeatNotFound
+}
+
+// ShowProcessList implements mariadb.Connector
+func (*FakeMariaDBFailWriteTestDataConnector) ShowProcessList() ([]Process, error) {
+	return []Process{}, nil
+}
+
+// KillConnection implements mariadb.Connector
+func (*FakeMariaDBFailWriteTestDataConnector) KillConnection(id uint64) error {
+	return nil
+}
+
 // InsertIDRecord implements mariadb.Connector
 func (*FakeMariaDBFailWriteTestDataConnector) InsertIDRecord(dbName string, tableName string, id int) error {
 	return nil
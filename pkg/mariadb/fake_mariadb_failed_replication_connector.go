@@ -14,6 +14,8 @@
 
 package mariadb
 
+import "time"
+
 type FakeMariaDBFailedReplicationConnector struct {
 }
 
@@ -46,6 +48,46 @@ func (c *FakeMariaDBFailedReplicationConnector) ShowReplicationStatus() (Replica
 	return status, nil
 }
 
+// ShowGTIDBinlogPos implements mariadb.Connector
+func (c *FakeMariaDBFailedReplicationConnector) ShowGTIDBinlogPos() (GTIDSet, error) {
+	return GTIDSet{}, nil
+}
+
+// ShowGTIDCurrentPos implements mariadb.Connector
+func (c *FakeMariaDBFailedReplicationConnector) ShowGTIDCurrentPos() (GTIDSet, error) {
+	return GTIDSet{}, nil
+}
+
+// ShowRemoteGTIDSlavePos implements mariadb.Connector
+func (c *FakeMariaDBFailedReplicationConnector) ShowRemoteGTIDSlavePos(remote RemoteInstance) (GTIDSet, error) {
+	return GTIDSet{}, nil
+}
+
+// ShowRemoteGTIDBinlogPos implements mariadb.Connector
+func (c *FakeMariaDBFailedReplicationConnector) ShowRemoteGTIDBinlogPos(remote RemoteInstance) (GTIDSet, error) {
+	return GTIDSet{}, nil
+}
+
+// RemoteBinlogContainsGTID implements mariadb.Connector
+func (c *FakeMariaDBFailedReplicationConnector) RemoteBinlogContainsGTID(remote RemoteInstance, gtid GTID) (bool, error) {
+	return true, nil
+}
+
+// Ping implements mariadb.Connector
+func (c *FakeMariaDBFailedReplicationConnector) Ping() error {
+	return nil
+}
+
+// ShowInnoDBSupport implements mariadb.Connector
+func (c *FakeMariaDBFailedReplicationConnector) ShowInnoDBSupport() (string, error) {
+	return "DEFAULT", nil
+}
+
+// MasterGTIDWait implements mariadb.Connector
+func (c *FakeMariaDBFailedReplicationConnector) MasterGTIDWait(pos GTIDSet, timeout time.Duration) error {
+	return nil
+}
+
 // StartReplica implements mariadb.Connector
 func (c *FakeMariaDBFailedReplicationConnector) StartReplica() error {
 	return nil
@@ -81,6 +123,31 @@ func (*FakeMariaDBFailedReplicationConnector) DeleteRecords(dbName string, table
 	return nil
 }
 
+// CreateHeartbeatTable implements mariadb.Connector
+func (*FakeMariaDBFailedReplicationConnector) CreateHeartbeatTable(dbName string, tableName string) error {
+	return nil
+}
+
+// WriteHeartbeat implements mariadb.Connector
+func (*FakeMariaDBFailedReplicationConnector) WriteHeartbeat(dbName string, tableName string, timestamp time.Time, epoch uint64) error {
+	return nil
+}
+
+// ShowHeartbeat implements mariadb.Connector
+func (*FakeMariaDBFailedReplicationConnector) ShowHeartbeat(dbName string, tableName string) (Heartbeat, error) {
+	return Heartbeat{}, ErrHeartbeatNotFound
+}
+
+// ShowProcessList implements mariadb.Connector
+func (*FakeMariaDBFailedReplicationConnector) ShowProcessList() ([]Process, error) {
+	return []Process{}, nil
+}
+
+// KillConnection implements mariadb.Connector
+func (*FakeMariaDBFailedReplicationConnector) KillConnection(id uint64) error {
+	return nil
+}
+
 // InsertIDRecord implements mariadb.Connector
 func (*FakeMariaDBFailedReplicationConnector) InsertIDRecord(dbName string, tableName string, id int) error {
 	return nil
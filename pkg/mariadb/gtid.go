@@ -0,0 +1,155 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mariadb
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// GTID is a MariaDB global transaction ID that is formatted as "domain-server-sequence".
+type GTID struct {
+	DomainID uint32
+	ServerID uint32
+	SeqNo    uint64
+}
+
+// String returns the MariaDB notation of the GTID.
+func (g GTID) String() string {
+	return fmt.Sprintf("%d-%d-%d", g.DomainID, g.ServerID, g.SeqNo)
+}
+
+// GTIDSet holds the last GTID of each replication domain.
+// that is the same representation as @@gtid_binlog_pos or @@gtid_slave_pos.
+type GTIDSet map[uint32]GTID
+
+// ParseGTID parses the string formatted as "domain-server-sequence".
+func ParseGTID(s string) (GTID, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) != 3 {
+		return GTID{}, fmt.Errorf("invalid gtid: %s", s)
+	}
+
+	domainID, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return GTID{}, fmt.Errorf("invalid domain id of gtid %s: %w", s, err)
+	}
+	serverID, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return GTID{}, fmt.Errorf("invalid server id of gtid %s: %w", s, err)
+	}
+	seqNo, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil {
+		return GTID{}, fmt.Errorf("invalid sequence number of gtid %s: %w", s, err)
+	}
+
+	return GTID{DomainID: uint32(domainID), ServerID: uint32(serverID), SeqNo: seqNo}, nil
+}
+
+// ParseGTIDSet parses the comma-separated GTID list like "0-1-100,1-2-20".
+// the empty string is parsed into the empty set.
+func ParseGTIDSet(s string) (GTIDSet, error) {
+	set := GTIDSet{}
+
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		gtid, err := ParseGTID(part)
+		if err != nil {
+			return nil, err
+		}
+		set[gtid.DomainID] = gtid
+	}
+
+	return set, nil
+}
+
+// Contains returns true if the set has applied all of the transactions in other.
+// in other words, each domain in other must be at the same or a smaller sequence number in s.
+func (s GTIDSet) Contains(other GTIDSet) bool {
+	for domainID, o := range other {
+		g, ok := s[domainID]
+		if !ok || g.SeqNo < o.SeqNo {
+			return false
+		}
+	}
+
+	return true
+}
+
+// GTIDHistory returns true if the server has recorded the gtid in its history.
+type GTIDHistory func(gtid GTID) (bool, error)
+
+// ErrantAgainst returns the GTIDs in s that the given primary has never seen.
+// a GTID is errant if the primary is behind it in the same domain,
+// or the primary has the same sequence number that is written by another server.
+// the GTID behind the primary position is looked up in the history of the primary,
+// because the primary may have written another transaction at that sequence number after a failover.
+func (s GTIDSet) ErrantAgainst(primary GTIDSet, history GTIDHistory) (GTIDSet, error) {
+	errant := GTIDSet{}
+	for domainID, g := range s {
+		p, ok := primary[domainID]
+		switch {
+		case !ok || p.SeqNo < g.SeqNo:
+			errant[domainID] = g
+		case p.SeqNo == g.SeqNo:
+			if p.ServerID != g.ServerID {
+				errant[domainID] = g
+			}
+		default:
+			recorded, err := history(g)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up gtid %s in the history of the primary: %w", g.String(), err)
+			}
+			if !recorded {
+				errant[domainID] = g
+			}
+		}
+	}
+
+	return errant, nil
+}
+
+// Transactions returns the total sequence number of all domains.
+// note that the total of multiple domains doesn't tell which node is more advanced,
+// because a node can be ahead in a domain and behind in another.
+func (s GTIDSet) Transactions() uint64 {
+	var total uint64
+	for _, g := range s {
+		total += g.SeqNo
+	}
+
+	return total
+}
+
+// String returns the MariaDB notation of the set that is sorted by the domain id.
+func (s GTIDSet) String() string {
+	domainIDs := make([]uint32, 0, len(s))
+	for domainID := range s {
+		domainIDs = append(domainIDs, domainID)
+	}
+	slices.Sort(domainIDs)
+
+	gtids := make([]string, len(domainIDs))
+	for i, domainID := range domainIDs {
+		gtids[i] = s[domainID].String()
+	}
+
+	return strings.Join(gtids, ",")
+}
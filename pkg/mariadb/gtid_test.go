@@ -0,0 +1,109 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mariadb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGTIDSet(t *testing.T) {
+	set, err := ParseGTIDSet("0-1-100,1-2-20")
+	assert.NoError(t, err)
+
+	assert.Equal(t, GTID{DomainID: 0, ServerID: 1, SeqNo: 100}, set[0])
+	assert.Equal(t, GTID{DomainID: 1, ServerID: 2, SeqNo: 20}, set[1])
+	assert.Equal(t, "0-1-100,1-2-20", set.String())
+}
+
+func TestParseGTIDSet_Empty(t *testing.T) {
+	set, err := ParseGTIDSet("")
+	assert.NoError(t, err)
+	assert.Len(t, set, 0)
+}
+
+func TestParseGTIDSet_Invalid(t *testing.T) {
+	_, err := ParseGTIDSet("0-1")
+	assert.Error(t, err)
+}
+
+func TestGTIDSetContains(t *testing.T) {
+	primary, _ := ParseGTIDSet("0-1-100")
+
+	caughtUp, _ := ParseGTIDSet("0-1-100")
+	assert.True(t, caughtUp.Contains(primary))
+
+	behind, _ := ParseGTIDSet("0-1-99")
+	assert.False(t, behind.Contains(primary))
+
+	otherDomain, _ := ParseGTIDSet("1-1-200")
+	assert.False(t, otherDomain.Contains(primary))
+}
+
+func TestGTIDSetErrantAgainst(t *testing.T) {
+	primary, _ := ParseGTIDSet("0-2-150,1-2-5")
+	// the primary took over at 0-1-100 and has written since 0-2-101.
+	history := func(gtid GTID) (bool, error) {
+		if gtid.DomainID == 0 && gtid.SeqNo <= 100 {
+			return gtid.ServerID == 1, nil
+		}
+		return gtid.ServerID == 2, nil
+	}
+
+	behind, _ := ParseGTIDSet("0-1-100")
+	errant, err := behind.ErrantAgainst(primary, history)
+	assert.NoError(t, err)
+	assert.Empty(t, errant)
+
+	caughtUp, _ := ParseGTIDSet("0-2-150,1-2-5")
+	errant, err = caughtUp.ErrantAgainst(primary, history)
+	assert.NoError(t, err)
+	assert.Empty(t, errant)
+
+	ahead, _ := ParseGTIDSet("0-1-151,1-2-5")
+	errant, err = ahead.ErrantAgainst(primary, history)
+	assert.NoError(t, err)
+	assert.Equal(t, "0-1-151", errant.String())
+
+	samePosByAnotherServer, _ := ParseGTIDSet("0-1-150")
+	errant, err = samePosByAnotherServer.ErrantAgainst(primary, history)
+	assert.NoError(t, err)
+	assert.Equal(t, "0-1-150", errant.String())
+
+	// the old primary lost the write and rejoins after the new primary has moved on.
+	lostWrite, _ := ParseGTIDSet("0-1-101")
+	errant, err = lostWrite.ErrantAgainst(primary, history)
+	assert.NoError(t, err)
+	assert.Equal(t, "0-1-101", errant.String())
+
+	unknownDomain, _ := ParseGTIDSet("2-1-1")
+	errant, err = unknownDomain.ErrantAgainst(primary, history)
+	assert.NoError(t, err)
+	assert.Equal(t, "2-1-1", errant.String())
+
+	purged := func(gtid GTID) (bool, error) { return false, errors.New("purged") }
+	_, err = behind.ErrantAgainst(primary, purged)
+	assert.Error(t, err)
+}
+
+func TestGTIDSetTransactions(t *testing.T) {
+	set, err := ParseGTIDSet("0-1-100,1-2-20")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(120), set.Transactions())
+
+	assert.Equal(t, uint64(0), GTIDSet{}.Transactions())
+}
@@ -0,0 +1,34 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mariadb
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrHeartbeatNotFound is returned when the heartbeat table has no row.
+var ErrHeartbeatNotFound = errors.New("no heartbeat is written")
+
+// Heartbeat is the row of the heartbeat table that the primary writes periodically.
+// the replicas measure the end-to-end replication lag by comparing the timestamp with their clock.
+type Heartbeat struct {
+	// ServerID is @@server_id of the primary that wrote the heartbeat.
+	ServerID uint32
+	// Timestamp is the time when the primary wrote the heartbeat.
+	Timestamp time.Time
+	// Epoch is the epoch of the primary controller.
+	Epoch uint64
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mariadb
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// processListQuery lists the sessions except the one that runs this query.
+// the open transaction is joined from innodb_trx because the session in a transaction may look idle.
+const processListQuery = "select p.id, p.user, p.host, ifnull(p.db, ''), p.command, p.time, t.trx_id is not null" +
+	" from information_schema.processlist p left join information_schema.innodb_trx t on t.trx_mysql_thread_id = p.id" +
+	" where p.id <> connection_id()"
+
+var (
+	// serverProcessUsers are the users of the sessions that MariaDB runs by itself.
+	serverProcessUsers = []string{"system user", "event_scheduler"}
+	// serverProcessCommands are the commands of the sessions that MariaDB runs by itself.
+	// the binlog dump is the replication to the replica, that must survive the draining for the switchover.
+	serverProcessCommands = []string{"Daemon", "Binlog Dump", "Binlog Dump GTID", "Slave_IO", "Slave_SQL", "Slave_worker"}
+)
+
+// Process is the session of the processlist.
+type Process struct {
+	ID      uint64
+	User    string
+	Host    string
+	DB      string
+	Command string
+	// Time is the time that the session has been in the current command.
+	Time time.Duration
+	// InTransaction is true if the session has an open InnoDB transaction.
+	InTransaction bool
+}
+
+// IsClientSession returns true if the session is connected from the client, not run by MariaDB itself.
+func (p Process) IsClientSession() bool {
+	return !slices.Contains(serverProcessUsers, p.User) && !slices.Contains(serverProcessCommands, p.Command)
+}
+
+// IsBusy returns true if the session is running a query or has an open transaction.
+func (p Process) IsBusy() bool {
+	return p.Command != "Sleep" || p.InTransaction
+}
+
+// String returns the session in the form of "id(user@host)".
+func (p Process) String() string {
+	return fmt.Sprintf("%d(%s@%s)", p.ID, p.User, p.Host)
+}
+
+// parseProcessListOutput parses the output of the "mysql -s -N -e" with processListQuery.
+func parseProcessListOutput(out string) ([]Process, error) {
+	processes := make([]Process, 0)
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("unexpected processlist row: %s", line)
+		}
+		id, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id of processlist: %w", err)
+		}
+		sec, err := strconv.ParseInt(fields[5], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time of processlist: %w", err)
+		}
+
+		processes = append(processes, Process{
+			ID:            id,
+			User:          fields[1],
+			Host:          fields[2],
+			DB:            fields[3],
+			Command:       fields[4],
+			Time:          time.Duration(sec) * time.Second,
+			InTransaction: fields[6] == "1",
+		})
+	}
+
+	return processes, nil
+}
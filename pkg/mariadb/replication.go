@@ -24,6 +24,14 @@ type MasterInstance struct {
 	UseGTID  MasterUseGTIDValue
 }
 
+// RemoteInstance specifies the MariaDB instance running on the other node.
+type RemoteInstance struct {
+	Host     string
+	Port     uint16
+	User     string
+	Password string
+}
+
 const (
 	MasterUseGTIDValueCurrentPos MasterUseGTIDValue = "current_pos"
 	MasterUseGTIDValueSlavePos   MasterUseGTIDValue = "slave_pos"
@@ -33,12 +41,15 @@ const (
 type ReplicationStatus map[string]string
 
 const (
-	ReplicationStatusSlaveIORunning     = "Slave_IO_Running"
-	ReplicationStatusSlaveSQLRunning    = "Slave_SQL_Running"
-	ReplicationStatusReadMasterLogPos   = "Read_Master_Log_Pos"
-	ReplicationStatusRelayMasterLogFile = "Relay_Master_Log_File"
-	ReplicationStatusMasterLogFile      = "Master_Log_File"
-	ReplicationStatusExecMasterLogPos   = "Exec_Master_Log_Pos"
+	ReplicationStatusMasterHost          = "Master_Host"
+	ReplicationStatusSlaveIORunning      = "Slave_IO_Running"
+	ReplicationStatusSlaveSQLRunning     = "Slave_SQL_Running"
+	ReplicationStatusReadMasterLogPos    = "Read_Master_Log_Pos"
+	ReplicationStatusRelayMasterLogFile  = "Relay_Master_Log_File"
+	ReplicationStatusMasterLogFile       = "Master_Log_File"
+	ReplicationStatusExecMasterLogPos    = "Exec_Master_Log_Pos"
+	ReplicationStatusSecondsBehindMaster = "Seconds_Behind_Master"
+	ReplicationStatusGtidIOPos           = "Gtid_IO_Pos"
 
 	ReplicationStatusSlaveIORunningYes  = "Yes"
 	ReplicationStatusSlaveSQLRunningYes = "Yes"
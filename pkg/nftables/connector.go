@@ -17,6 +17,7 @@ package nftables
 import (
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/sakura-internet/distributed-mariadb-controller/pkg/command"
@@ -35,6 +36,8 @@ type Connector interface {
 	FlushChain(chain string) error
 	CreateChain(chain string) error
 	AddRule(chain string, matches []Match, statement statement) error
+	// ListRules returns the rules in the chain. each rule is formatted in the nft syntax.
+	ListRules(chain string) ([]string, error)
 }
 
 // nftCommandConnector is a default implementation of Connector.
@@ -79,6 +82,36 @@ func (c *nftCommandConnector) FlushChain(
 	return nil
 }
 
+// ListRules implements Connector
+func (c *nftCommandConnector) ListRules(
+	chain string,
+) ([]string, error) {
+	name := "nft"
+	args := []string{"list", "chain", builtinTableFilter, chain}
+	c.logger.Debug("execute command", "name", name, "args", args)
+	out, err := command.RunWithTimeout(nftCommandTimeout, name, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chain %s on table %s: %w", chain, builtinTableFilter, err)
+	}
+
+	return parseListChainOutput(string(out)), nil
+}
+
+// parseListChainOutput extracts the rules from the output of the "nft list chain".
+func parseListChainOutput(out string) []string {
+	rules := []string{}
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasSuffix(line, "{") || line == "}" || strings.HasPrefix(line, "type ") {
+			continue
+		}
+
+		rules = append(rules, line)
+	}
+
+	return rules
+}
+
 // CreateChain implements Connector
 func (c *nftCommandConnector) CreateChain(
 	chain string,
@@ -0,0 +1,33 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nftables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseListChainOutput(t *testing.T) {
+	const input = `table ip filter {
+	chain mariadb {
+		type filter hook input priority filter; policy accept;
+		iifname "eth0" tcp dport 3306 accept
+	}
+}`
+
+	rules := parseListChainOutput(input)
+	assert.Equal(t, []string{`iifname "eth0" tcp dport 3306 accept`}, rules)
+}
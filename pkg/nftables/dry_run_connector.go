@@ -0,0 +1,59 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nftables
+
+import (
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/command"
+)
+
+// dryRunConnector is an implementation of Connector.
+// this impl records the commands that change the rules instead of running them.
+// listing the rules is delegated to the underlying connector.
+type dryRunConnector struct {
+	connector Connector
+	recorder  *command.Recorder
+}
+
+func NewDryRunConnector(connector Connector, recorder *command.Recorder) Connector {
+	return &dryRunConnector{connector: connector, recorder: recorder}
+}
+
+// AddRule implements Connector
+func (c *dryRunConnector) AddRule(chain string, matches []Match, stmt statement) error {
+	args := []string{"add", "rule", builtinTableFilter, chain}
+	for _, match := range matches {
+		args = append(args, match...)
+	}
+	args = append(args, stmt...)
+	c.recorder.Record("nftables", "nft", args...)
+	return nil
+}
+
+// FlushChain implements Connector
+func (c *dryRunConnector) FlushChain(chain string) error {
+	c.recorder.Record("nftables", "nft", "flush", "chain", builtinTableFilter, chain)
+	return nil
+}
+
+// CreateChain implements Connector
+func (c *dryRunConnector) CreateChain(chain string) error {
+	c.recorder.Record("nftables", "nft", "add", "chain", builtinTableFilter, chain, "{ type filter hook input priority 0; }")
+	return nil
+}
+
+// ListRules implements Connector
+func (c *dryRunConnector) ListRules(chain string) ([]string, error) {
+	return c.connector.ListRules(chain)
+}
@@ -15,6 +15,7 @@
 package nftables
 
 import (
+	"strings"
 	"time"
 )
 
@@ -22,20 +23,36 @@ import (
 type FakeNftablesConnector struct {
 	// Timestamp holds the method calling's timestamp.
 	Timestamp map[string]time.Time
+	// Rules holds the rules of each chain in the nft syntax.
+	Rules map[string][]string
 }
 
 // AddRule implements nftables.Connector
 func (c *FakeNftablesConnector) AddRule(chain string, matches []Match, statement statement) error {
 	c.Timestamp["AddRule"] = time.Now()
+
+	rule := []string{}
+	for _, match := range matches {
+		rule = append(rule, match...)
+	}
+	rule = append(rule, statement...)
+	c.Rules[chain] = append(c.Rules[chain], strings.Join(rule, " "))
 	return nil
 }
 
 // FlushChain implements nftables.Connector
 func (c *FakeNftablesConnector) FlushChain(chain string) error {
 	c.Timestamp["FlushChain"] = time.Now()
+	c.Rules[chain] = nil
 	return nil
 }
 
+// ListRules implements nftables.Connector
+func (c *FakeNftablesConnector) ListRules(chain string) ([]string, error) {
+	c.Timestamp["ListRules"] = time.Now()
+	return c.Rules[chain], nil
+}
+
 // CreateChain implements nftables.Connector
 func (c *FakeNftablesConnector) CreateChain(chain string) error {
 	c.Timestamp["CreateChain"] = time.Now()
@@ -45,5 +62,6 @@ func (c *FakeNftablesConnector) CreateChain(chain string) error {
 func NewFakeNftablesConnector() Connector {
 	return &FakeNftablesConnector{
 		Timestamp: make(map[string]time.Time),
+		Rules:     make(map[string][]string),
 	}
 }
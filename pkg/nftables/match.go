@@ -28,6 +28,11 @@ func TCPDstPortMatch(dport uint16) Match {
 	return []string{"tcp", "dport", strconv.Itoa(int(dport))}
 }
 
+// CTStateMatch matches the packets by the conntrack state such as "new" and "established".
+func CTStateMatch(state string) Match {
+	return []string{"ct", "state", state}
+}
+
 func IFNameMatch(ifname string) Match {
 	return []string{"iifname", ifname}
 }
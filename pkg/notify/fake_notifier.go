@@ -0,0 +1,58 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"sync"
+)
+
+// FakeNotifier is for testing the controller.
+type FakeNotifier struct {
+	m sync.Mutex
+	// Events holds the events that Notify() is called with.
+	Events []Event
+	// Closed is true after Close() is called.
+	Closed bool
+}
+
+func NewFakeNotifier() Notifier {
+	return &FakeNotifier{}
+}
+
+// Notify implements notify.Notifier
+func (n *FakeNotifier) Notify(event Event) {
+	n.m.Lock()
+	defer n.m.Unlock()
+	n.Events = append(n.Events, event)
+}
+
+// Close implements notify.Notifier
+func (n *FakeNotifier) Close(ctx context.Context) {
+	n.m.Lock()
+	defer n.m.Unlock()
+	n.Closed = true
+}
+
+// EventTypes returns the types of the notified events in order.
+func (n *FakeNotifier) EventTypes() []EventType {
+	n.m.Lock()
+	defer n.m.Unlock()
+	types := make([]EventType, len(n.Events))
+	for i, e := range n.Events {
+		types[i] = e.Type
+	}
+	return types
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// EventType is the type of the event that the controller notifies.
+type EventType string
+
+const (
+	// EventStateChanged is notified when the controller transitions to another state.
+	EventStateChanged EventType = "state-changed"
+	// EventDualPrimary is notified when the controller finds two or more primaries.
+	EventDualPrimary EventType = "dual-primary"
+	// EventWriteTestFailed is notified when the primary fails to write the test data to MariaDB.
+	EventWriteTestFailed EventType = "write-test-failed"
+	// EventReplicationThresholdExhausted is notified when the replica reaches the maximum retry limit of the replication.
+	EventReplicationThresholdExhausted EventType = "replication-threshold-exhausted"
+	// EventNeighborsChanged is notified when the set of the BGP neighbors is updated.
+	EventNeighborsChanged EventType = "neighbors-changed"
+	// EventSessionsKilled is notified when the demoted primary kills the client sessions after the draining.
+	EventSessionsKilled EventType = "sessions-killed"
+)
+
+const (
+	// SignatureHeader is the HTTP header that holds the HMAC-SHA256 signature of the request body.
+	SignatureHeader = "X-DBC-Signature"
+	// EventTypeHeader is the HTTP header that holds the type of the event.
+	EventTypeHeader = "X-DBC-Event"
+)
+
+// Event is the event that is sent to the notifiers as JSON.
+type Event struct {
+	Type        EventType `json:"type"`
+	Time        time.Time `json:"time"`
+	HostAddress string    `json:"host_address"`
+	// State is the state of the controller when the event occurred.
+	State string `json:"state"`
+	// From, To and Reason describe the transition of EventStateChanged.
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	// Message is the human-readable detail of the event.
+	Message string `json:"message,omitempty"`
+	// Neighbors holds the addresses of the visible neighbors by their state.
+	Neighbors map[string][]string `json:"neighbors,omitempty"`
+}
+
+// Notifier is an interface that delivers the events to the outside.
+type Notifier interface {
+	// Notify sends the event. the implementation must not block the controller loop.
+	Notify(event Event)
+	// Close stops accepting the events and waits until the queued events are delivered.
+	// the events that aren't delivered until the context is done are dropped.
+	Close(ctx context.Context)
+}
+
+// Sign returns the signature of the body in the form of "sha256=<hex encoded HMAC-SHA256>".
+// the receiver verifies the request by comparing the signature header with this.
+func Sign(secret []byte, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,183 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/command"
+	"github.com/stretchr/testify/assert"
+)
+
+func _newTestLogger() *slog.Logger {
+	return slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{}))
+}
+
+func TestSign(t *testing.T) {
+	// the well-known test vector of HMAC-SHA256.
+	assert.Equal(t,
+		"sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8",
+		Sign([]byte("key"), []byte("The quick brown fox jumps over the lazy dog")))
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var (
+		m         sync.Mutex
+		received  []Event
+		signature string
+		eventType string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		m.Lock()
+		defer m.Unlock()
+		var e Event
+		_ = json.Unmarshal(body, &e)
+		received = append(received, e)
+		eventType = r.Header.Get(EventTypeHeader)
+		if r.Header.Get(SignatureHeader) == Sign([]byte("secret"), body) {
+			signature = "valid"
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	n := NewWebhookNotifier(_newTestLogger(), srv.URL, []byte("secret"), time.Second, 0)
+	n.Notify(Event{Type: EventStateChanged, HostAddress: "10.0.0.1", From: "replica", To: "candidate"})
+	n.Notify(Event{Type: EventDualPrimary, HostAddress: "10.0.0.1"})
+	n.Close(context.Background())
+
+	m.Lock()
+	defer m.Unlock()
+	// the events are delivered in order.
+	assert.Len(t, received, 2)
+	assert.Equal(t, EventStateChanged, received[0].Type)
+	assert.Equal(t, "candidate", received[0].To)
+	assert.Equal(t, EventDualPrimary, received[1].Type)
+	assert.Equal(t, string(EventDualPrimary), eventType)
+	assert.Equal(t, "valid", signature)
+}
+
+func TestWebhookNotifier_Retry(t *testing.T) {
+	var (
+		m        sync.Mutex
+		attempts int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.Lock()
+		defer m.Unlock()
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	n := newWebhookNotifier(_newTestLogger(), srv.URL, nil, time.Second, 3, time.Millisecond)
+	assert.NoError(t, n.deliver(Event{Type: EventWriteTestFailed}))
+	assert.Equal(t, 3, attempts)
+
+	// the retries are exhausted.
+	attempts = 0
+	n = newWebhookNotifier(_newTestLogger(), srv.URL, nil, time.Second, 1, time.Millisecond)
+	assert.Error(t, n.deliver(Event{Type: EventWriteTestFailed}))
+	assert.Equal(t, 2, attempts)
+}
+
+func TestWebhookNotifier_NoRetryOnClientError(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	n := newWebhookNotifier(_newTestLogger(), srv.URL, nil, time.Second, 3, time.Millisecond)
+	assert.Error(t, n.deliver(Event{Type: EventNeighborsChanged}))
+	assert.Equal(t, 1, attempts)
+}
+
+func TestWebhookNotifier_CloseWithoutEvents(t *testing.T) {
+	n := NewWebhookNotifier(_newTestLogger(), "http://127.0.0.1:1", nil, time.Second, 0)
+	n.Close(context.Background())
+	// the events after Close are ignored.
+	n.Notify(Event{Type: EventStateChanged})
+	n.Close(context.Background())
+}
+
+func TestWebhookNotifier_CloseWithDeadline(t *testing.T) {
+	var (
+		m        sync.Mutex
+		attempts int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.Lock()
+		defer m.Unlock()
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	// the full retries of the queued events would take minutes.
+	n := newWebhookNotifier(_newTestLogger(), srv.URL, nil, time.Second, 10, time.Second)
+	for i := 0; i < 10; i++ {
+		n.Notify(Event{Type: EventWriteTestFailed})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	n.Close(ctx)
+	assert.Less(t, time.Since(start), time.Second)
+
+	m.Lock()
+	defer m.Unlock()
+	// the first event is given up during the backoff and the rest are dropped.
+	assert.Equal(t, 1, attempts)
+}
+
+func TestDryRunNotifier(t *testing.T) {
+	recorder := command.NewRecorder(_newTestLogger())
+	n := NewDryRunNotifier(recorder)
+	n.Notify(Event{Type: EventStateChanged, From: "primary", To: "fault", Reason: "decided"})
+
+	commands := recorder.Commands(0)
+	assert.Len(t, commands, 1)
+	assert.Equal(t, "notify", commands[0].Component)
+}
+
+func TestParseWebhookURLs(t *testing.T) {
+	urls, err := ParseWebhookURLs("https://hooks.example.com/a, http://192.0.2.10:8080/b")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://hooks.example.com/a", "http://192.0.2.10:8080/b"}, urls)
+
+	urls, err = ParseWebhookURLs("")
+	assert.NoError(t, err)
+	assert.Empty(t, urls)
+
+	_, err = ParseWebhookURLs("hooks.example.com/a")
+	assert.Error(t, err)
+}
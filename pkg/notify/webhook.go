@@ -0,0 +1,241 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/command"
+)
+
+const (
+	// webhookQueueSize is the number of the events that wait for the delivery.
+	// the events are dropped while the queue is full.
+	webhookQueueSize = 64
+	// defaultWebhookInitialBackoff is the wait before the first retry. the wait doubles on each retry.
+	defaultWebhookInitialBackoff = 1 * time.Second
+	// webhookMaxBackoff is the upper limit of the wait between the retries.
+	webhookMaxBackoff = 30 * time.Second
+)
+
+// webhookNotifier is an implementation of Notifier.
+// this impl POSTs the event as JSON to the HTTP endpoint such as the chat or the on-call service.
+// the events are delivered in order by the background goroutine, so the slow endpoint never blocks the controller loop.
+type webhookNotifier struct {
+	logger         *slog.Logger
+	url            string
+	secret         []byte
+	timeout        time.Duration
+	maxRetries     uint
+	initialBackoff time.Duration
+	client         *http.Client
+
+	// m protects closed and the send to queue.
+	m      sync.Mutex
+	closed bool
+	queue  chan Event
+	// start starts the delivery goroutine on the first event.
+	start sync.Once
+	// done is closed when the delivery goroutine exits.
+	done chan struct{}
+	// ctx is cancelled when Close gives up the delivery. that aborts the request and the backoff.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewWebhookNotifier creates the notifier that POSTs the events to the url.
+// the request is signed with the secret if it is not empty, see Sign.
+// the failed delivery is retried up to maxRetries times with the exponential backoff.
+func NewWebhookNotifier(logger *slog.Logger, url string, secret []byte, timeout time.Duration, maxRetries uint) Notifier {
+	return newWebhookNotifier(logger, url, secret, timeout, maxRetries, defaultWebhookInitialBackoff)
+}
+
+func newWebhookNotifier(logger *slog.Logger, url string, secret []byte, timeout time.Duration, maxRetries uint, initialBackoff time.Duration) *webhookNotifier {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &webhookNotifier{
+		logger:         logger,
+		url:            url,
+		secret:         secret,
+		timeout:        timeout,
+		maxRetries:     maxRetries,
+		initialBackoff: initialBackoff,
+		client:         &http.Client{},
+		queue:          make(chan Event, webhookQueueSize),
+		done:           make(chan struct{}),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
+
+// Notify implements Notifier
+func (n *webhookNotifier) Notify(event Event) {
+	n.m.Lock()
+	defer n.m.Unlock()
+	if n.closed {
+		return
+	}
+
+	n.start.Do(func() { go n.run() })
+	select {
+	case n.queue <- event:
+	default:
+		n.logger.Warn("the webhook queue is full. the event is dropped.", "url", n.url, "type", event.Type)
+	}
+}
+
+// Close implements Notifier
+// the unreachable endpoint never delays the exit of the controller beyond the context.
+func (n *webhookNotifier) Close(ctx context.Context) {
+	n.m.Lock()
+	if !n.closed {
+		n.closed = true
+		// nothing to wait if no event has been notified.
+		n.start.Do(func() { close(n.done) })
+		close(n.queue)
+	}
+	n.m.Unlock()
+
+	select {
+	case <-n.done:
+	case <-ctx.Done():
+		n.cancel()
+		<-n.done
+	}
+	n.cancel()
+}
+
+// run delivers the queued events until the queue is closed.
+// the events left after the delivery is given up are dropped.
+func (n *webhookNotifier) run() {
+	defer close(n.done)
+	dropped := 0
+	for event := range n.queue {
+		if n.ctx.Err() != nil {
+			dropped++
+			continue
+		}
+		if err := n.deliver(event); err != nil {
+			n.logger.Warn("failed to deliver the event to the webhook", "url", n.url, "type", event.Type, "error", err)
+		}
+	}
+	if dropped != 0 {
+		n.logger.Warn("the webhook events are dropped on close", "url", n.url, "dropped", dropped)
+	}
+}
+
+// deliver POSTs the event with the retries.
+func (n *webhookNotifier) deliver(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	backoff := n.initialBackoff
+	for attempt := uint(0); ; attempt++ {
+		retryable, err := n.post(event.Type, body)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= n.maxRetries {
+			return err
+		}
+
+		n.logger.Debug("retry the webhook", "url", n.url, "type", event.Type, "error", err, "backoff", backoff)
+		select {
+		case <-n.ctx.Done():
+			return fmt.Errorf("gave up the delivery: %w", err)
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, webhookMaxBackoff)
+	}
+}
+
+// post sends the body once. the function returns true with the error if the request is worth retrying.
+func (n *webhookNotifier) post(eventType EventType, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(n.ctx, n.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventTypeHeader, string(eventType))
+	if len(n.secret) != 0 {
+		req.Header.Set(SignatureHeader, Sign(n.secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to post to %s: %w", n.url, err)
+	}
+	defer resp.Body.Close()
+
+	if 200 <= resp.StatusCode && resp.StatusCode < 300 {
+		return false, nil
+	}
+	// the client errors except the rate limit never succeed by the retry.
+	retryable := resp.StatusCode == http.StatusTooManyRequests || 500 <= resp.StatusCode
+	return retryable, fmt.Errorf("failed to post to %s: unexpected status %d", n.url, resp.StatusCode)
+}
+
+// dryRunNotifier is an implementation of Notifier.
+// this impl records the events instead of sending them.
+type dryRunNotifier struct {
+	recorder *command.Recorder
+}
+
+func NewDryRunNotifier(recorder *command.Recorder) Notifier {
+	return &dryRunNotifier{recorder: recorder}
+}
+
+// Notify implements Notifier
+func (n *dryRunNotifier) Notify(event Event) {
+	args := []string{string(event.Type)}
+	if event.From != "" || event.To != "" {
+		args = append(args, event.From+"->"+event.To)
+	}
+	if event.Message != "" {
+		args = append(args, event.Message)
+	}
+	n.recorder.Record("notify", "webhook", args...)
+}
+
+// Close implements Notifier
+func (n *dryRunNotifier) Close(ctx context.Context) {}
+
+// ParseWebhookURLs parses the comma-separated webhook endpoints.
+func ParseWebhookURLs(s string) ([]string, error) {
+	urls := make([]string, 0)
+	for _, u := range strings.Split(s, ",") {
+		u = strings.TrimSpace(u)
+		if u == "" {
+			continue
+		}
+		if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+			return nil, fmt.Errorf("invalid webhook url %s: the scheme must be http or https", u)
+		}
+		urls = append(urls, u)
+	}
+
+	return urls, nil
+}
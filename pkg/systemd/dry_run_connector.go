@@ -0,0 +1,54 @@
+// Copyright 2025 The distributed-mariadb-controller Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package systemd
+
+import (
+	"github.com/sakura-internet/distributed-mariadb-controller/pkg/command"
+)
+
+// dryRunConnector is an implementation of Connector.
+// this impl records the commands that change the services instead of running them.
+// the status check is delegated to the underlying connector.
+type dryRunConnector struct {
+	connector Connector
+	recorder  *command.Recorder
+}
+
+func NewDryRunConnector(connector Connector, recorder *command.Recorder) Connector {
+	return &dryRunConnector{connector: connector, recorder: recorder}
+}
+
+// StartService implements Connector
+func (c *dryRunConnector) StartService(serviceName string) error {
+	c.recorder.Record("systemd", "systemctl", "start", serviceName)
+	return nil
+}
+
+// StopService implements Connector
+func (c *dryRunConnector) StopService(serviceName string) error {
+	c.recorder.Record("systemd", "systemctl", "stop", serviceName)
+	return nil
+}
+
+// KillService implements Connector
+func (c *dryRunConnector) KillService(serviceName string) error {
+	c.recorder.Record("systemd", "systemctl", "kill", "-s", "SIGKILL", serviceName)
+	return nil
+}
+
+// CheckServiceStatus implements Connector
+func (c *dryRunConnector) CheckServiceStatus(serviceName string) error {
+	return c.connector.CheckServiceStatus(serviceName)
+}